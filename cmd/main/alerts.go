@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/notify"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/rules"
+	"github.com/yourusername/fmcl/pkg/source"
+)
+
+// 高重要性事件的公布提醒和用户规则
+type alerter struct {
+	watcher    *notify.Watcher    // 未配置提醒方式时为 nil
+	dispatcher *notify.Dispatcher // 未配置提醒方式时为 nil
+	engine     *rules.Engine      // 未配置规则时为 nil
+	bell       io.Writer
+	cfg        *config.AppConfig
+}
+
+// 按 notification_methods 和 rules 创建提醒，两者都未配置时返回 nil。
+// 规则的时间窗口使用显示时区，重新加载配置后立即生效
+func newAlerter(cfg *config.AppConfig) (*alerter, error) {
+	a := &alerter{bell: os.Stdout, cfg: cfg}
+	if len(cfg.NotificationMethods) > 0 {
+		dispatcher, err := notify.New(cfg)
+		if err != nil {
+			return nil, err
+		}
+		lead := notify.DefaultLead
+		if cfg.Notification.LeadMinutes > 0 {
+			lead = time.Duration(cfg.Notification.LeadMinutes) * time.Minute
+		}
+		a.watcher = notify.NewWatcher(lead)
+		a.dispatcher = dispatcher
+	}
+
+	compiled, err := rules.Compile(cfg.Rules)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range compiled {
+		if r.Actions[rules.ActionNotify] && a.dispatcher == nil {
+			return nil, fmt.Errorf("规则 %s 使用 notify 动作，但没有配置 notification_methods", r.Name)
+		}
+	}
+	if len(compiled) > 0 {
+		a.engine = rules.NewEngine(compiled, cfg.Location())
+	}
+
+	if a.watcher == nil && a.engine == nil {
+		return nil, nil
+	}
+	return a, nil
+}
+
+// 检查今天的事件，在后台发送产生的提醒并返回它们。
+// 同时清除今天之前的提醒记录，以及本周和正在显示的日期之前的规则命中记录，
+// 正在显示的日期保留命中记录，刷新时不会重复响铃和提醒
+func (a *alerter) check(snapshots []source.Snapshot, today, now time.Time) []notify.Alert {
+	if a == nil {
+		return nil
+	}
+	if a.engine != nil {
+		keep := startOfWeek(today)
+		for _, snapshot := range snapshots {
+			if snapshot.Date.Before(keep) {
+				keep = snapshot.Date
+			}
+		}
+		a.engine.Prune(keep)
+	}
+	if a.watcher == nil {
+		return nil
+	}
+	a.watcher.Prune(today)
+	var alerts []notify.Alert
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Equal(today) {
+			alerts = append(alerts, a.watcher.Check(snapshot.Date, snapshot.Events, now)...)
+		}
+	}
+	if len(alerts) > 0 {
+		go a.dispatcher.Send(alerts...)
+	}
+	return alerts
+}
+
+// 用规则匹配一天的事件：命中 highlight 的事件记入 ann，
+// 首次命中时执行响铃、提醒和日志动作，返回首次命中
+func (a *alerter) applyRules(snapshot source.Snapshot, ann *annotations) []rules.Hit {
+	if a == nil || a.engine == nil {
+		return nil
+	}
+
+	var items []rules.Item
+	for i := range snapshot.Events {
+		e := &snapshot.Events[i]
+		items = append(items, rules.Item{Calendar: e, Surprise: ann.surprises[eventKey(e.Time, e.Region, e.Indicator)]})
+	}
+	for i := range snapshot.ImportantEvents {
+		items = append(items, rules.Item{Important: &snapshot.ImportantEvents[i]})
+	}
+
+	a.engine.Location = a.cfg.Location()
+	var fresh []rules.Hit
+	var alerts []notify.Alert
+	for _, hit := range a.engine.Evaluate(snapshot.Date, items) {
+		it := hit.Item
+		var key string
+		event := it.Calendar
+		if event != nil {
+			key = eventKey(event.Time, event.Region, event.Indicator)
+		} else {
+			key = eventKey(it.Important.Time, it.Important.Region, it.Important.Event)
+			event = &parser.CalendarEvent{
+				Time:       it.Important.Time,
+				Region:     it.Important.Region,
+				Indicator:  it.Important.Event,
+				Importance: it.Important.Importance,
+				At:         it.Important.At,
+			}
+		}
+		if hit.Rule.Actions[rules.ActionHighlight] {
+			if ann.highlighted == nil {
+				ann.highlighted = make(map[string]bool)
+			}
+			ann.highlighted[key] = true
+		}
+		if !hit.New {
+			continue
+		}
+
+		fresh = append(fresh, hit)
+		if hit.Rule.Actions[rules.ActionSound] {
+			io.WriteString(a.bell, "\a")
+		}
+		if hit.Rule.Actions[rules.ActionLog] {
+			logger.Info("规则命中",
+				zap.String("rule", hit.Rule.Name),
+				zap.String("date", snapshot.Date.Format("2006-01-02")),
+				zap.String("time", event.Time),
+				zap.String("region", event.Region),
+				zap.String("indicator", event.Indicator),
+				zap.String("actual", event.Actual))
+		}
+		if hit.Rule.Actions[rules.ActionNotify] {
+			alerts = append(alerts, notify.Alert{Kind: notify.KindRule, Date: snapshot.Date, Event: *event, Rule: hit.Rule.Name})
+		}
+	}
+	if len(alerts) > 0 {
+		go a.dispatcher.Send(alerts...)
+	}
+	return fresh
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// 子命令执行时的环境
+type cliContext struct {
+	cfg     *config.AppConfig
+	options config.Options // 加载配置所用的选项，用于热加载
+	args    []string       // 参数之后的其余命令行
+	out     io.Writer
+}
+
+// 子命令
+type command struct {
+	name    string
+	summary string
+	// setup 在 fs 上注册子命令的参数，返回执行函数
+	setup func(fs *flag.FlagSet) func(c *cliContext) error
+}
+
+// 全部子命令，不带子命令时运行 tui
+var commands = []command{
+	{"tui", "终端界面（默认）", tuiCommand},
+	{"list", "输出某一天的财经日历事件", listCommand},
+	{"events", "输出某一天的重要事件", eventsCommand},
+	{"rates", "输出央行利率", ratesCommand},
+	{"fetch", "获取数据并保存到数据库，不显示", fetchCommand},
+	{"history", "查询数据库中的历史数据", historyCommand},
+	{"export", "按显示模式导出为 CSV、JSON Lines 或 xlsx", exportCommand},
+	{"ics", "导出 iCalendar (.ics) 日历", icsCommand},
+	{"serve", "定时获取数据并提供 HTTP/JSON 接口", serveCommand},
+	{"db", "数据库迁移: db migrate | db status", dbCommand},
+}
+
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+// 输出命令用法
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: fmcl [命令] [参数]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "命令:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "使用 fmcl <命令> -h 查看命令的参数")
+}
+
+// 输出格式
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// 注册 list、events、rates 共用的 --date 和 --format 参数
+func outputFlags(fs *flag.FlagSet) (date, format *string) {
+	date = fs.String("date", "", "日期 YYYY-MM-DD 或 YYYYMMDD，默认今天（数据源时区）")
+	format = fs.String("format", formatTable, "输出格式: table json csv")
+	return date, format
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	}
+	return fmt.Errorf("未知的输出格式 %q（可选 table、json、csv）", format)
+}
+
+// 注册 list 命令的参数
+func listCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	date, format := outputFlags(fs)
+	importance := fs.String("importance", "all", "重要性: high medium low all（也可用 高 中 低）")
+	return func(c *cliContext) error {
+		level, err := parser.ParseImportance(*importance)
+		if err != nil {
+			return err
+		}
+		snapshot, err := cliSnapshot(c.cfg, *date, *format)
+		if err != nil {
+			return err
+		}
+		events := filterImportance(snapshot.Events, level)
+		return writeEvents(c.out, *format, events, c.cfg.Location(), snapshot.Date)
+	}
+}
+
+// 注册 events 命令的参数
+func eventsCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	date, format := outputFlags(fs)
+	return func(c *cliContext) error {
+		snapshot, err := cliSnapshot(c.cfg, *date, *format)
+		if err != nil {
+			return err
+		}
+		return writeImportantEvents(c.out, *format, snapshot.ImportantEvents, c.cfg.Location(), snapshot.Date)
+	}
+}
+
+// 注册 rates 命令的参数
+func ratesCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	date, format := outputFlags(fs)
+	return func(c *cliContext) error {
+		snapshot, err := cliSnapshot(c.cfg, *date, *format)
+		if err != nil {
+			return err
+		}
+		return writeRates(c.out, *format, snapshot.Rates)
+	}
+}
+
+// 注册 fetch 命令的参数
+func fetchCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	date := fs.String("date", "", "起始日期 YYYY-MM-DD 或 YYYYMMDD，默认今天（数据源时区）")
+	days := fs.Int("days", 1, "获取的天数")
+	archiveDir := fs.String("archive", "", "将下载的原始页面压缩归档到指定目录")
+	return func(c *cliContext) error {
+		if *days <= 0 {
+			return fmt.Errorf("天数必须大于0: %d", *days)
+		}
+		var fetcher htmlfetcher.Fetcher = &htmlfetcher.DefaultFetcher{}
+		if *archiveDir != "" {
+			fetcher = htmlfetcher.NewArchivingFetcher(fetcher, *archiveDir)
+		}
+		src, db, err := openData(c.cfg, fetcher)
+		if err != nil {
+			return err
+		}
+		defer db.Close()
+
+		from, err := cliDate(*date, src.Location())
+		if err != nil {
+			return err
+		}
+		snapshots, err := src.Fetch(from, from.AddDate(0, 0, *days-1))
+		if err != nil {
+			return fmt.Errorf("获取数据失败: %v", err)
+		}
+		for _, snapshot := range snapshots {
+			saveSnapshot(db, snapshot)
+			fmt.Fprintf(c.out, "%s  %s  财经日历 %d  重要事件 %d  央行利率 %d\n",
+				snapshot.Date.Format("2006-01-02"), snapshot.Source,
+				len(snapshot.Events), len(snapshot.ImportantEvents), len(snapshot.Rates))
+		}
+		return nil
+	}
+}
+
+// 注册 db 命令
+func dbCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	return func(c *cliContext) error {
+		return runDB(c.args, c.cfg.DatabasePath)
+	}
+}
+
+// 打开数据库并按配置创建数据源
+func openData(cfg *config.AppConfig, fetcher htmlfetcher.Fetcher) (source.Source, *storage.DB, error) {
+	parser.RegisterColumnAliases(cfg.ColumnAliases)
+	src, err := source.NewFromConfig(cfg.Sources, fetcher)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化数据源失败: %v", err)
+	}
+	db, err := storage.NewDB(cfg.DatabasePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("初始化数据库失败: %v", err)
+	}
+	return src, db, nil
+}
+
+// 解析 --date 参数，为空时返回 loc 时区下的今天
+func cliDate(input string, loc *time.Location) (time.Time, error) {
+	if input == "" {
+		return startOfDay(time.Now(), loc), nil
+	}
+	return parseDateInput(input, loc)
+}
+
+// 获取 list、events、rates 所需的某一天数据，与界面相同：
+// 过去的日期优先使用本地存储，其余从数据源获取并保存
+func cliSnapshot(cfg *config.AppConfig, date, format string) (source.Snapshot, error) {
+	if err := checkFormat(format); err != nil {
+		return source.Snapshot{}, err
+	}
+	src, db, err := openData(cfg, &htmlfetcher.DefaultFetcher{})
+	if err != nil {
+		return source.Snapshot{}, err
+	}
+	defer db.Close()
+
+	day, err := cliDate(date, src.Location())
+	if err != nil {
+		return source.Snapshot{}, err
+	}
+	return loadDay(src, db, day, startOfDay(time.Now(), src.Location()), false)
+}
+
+// 按重要性过滤财经日历事件，level 为空时不过滤
+func filterImportance(events []parser.CalendarEvent, level string) []parser.CalendarEvent {
+	filtered := []parser.CalendarEvent{}
+	for _, event := range events {
+		if level == "" || event.Importance == level {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// 财经日历事件的表头，与界面一致
+var eventColumns = []string{"时间", "地区", "指标", "前值", "预测", "公布值", "重要性", "利多利空"}
+
+// 输出财经日历事件。表格和CSV中的时间按显示时区 loc 换算
+func writeEvents(w io.Writer, format string, events []parser.CalendarEvent, loc *time.Location, day time.Time) error {
+	if format == formatJSON {
+		return writeJSON(w, events)
+	}
+	rows := make([][]string, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, []string{
+			displayTime(e.Time, e.At, day, loc), e.Region, e.Indicator,
+			e.Previous, e.Forecast, e.Actual, e.Importance, e.Impact,
+		})
+	}
+	return writeRows(w, format, eventColumns, rows)
+}
+
+// 重要事件的表头
+var importantEventColumns = []string{"时间", "国家地区", "地点", "重要性", "事件"}
+
+// 输出重要事件
+func writeImportantEvents(w io.Writer, format string, events []parser.ImportantEvent, loc *time.Location, day time.Time) error {
+	if format == formatJSON {
+		if events == nil {
+			events = []parser.ImportantEvent{}
+		}
+		return writeJSON(w, events)
+	}
+	rows := make([][]string, 0, len(events))
+	for _, e := range events {
+		rows = append(rows, []string{displayTime(e.Time, e.At, day, loc), e.Region, e.Location, e.Importance, e.Event})
+	}
+	return writeRows(w, format, importantEventColumns, rows)
+}
+
+// 央行利率的表头
+var rateColumns = []string{"央行", "利率名称", "当前值", "前次值", "最近变动", "历史峰值", "历史最低", "下次预测", "CPI"}
+
+// 输出央行利率
+func writeRates(w io.Writer, format string, rates []parser.CentralBankRate) error {
+	if format == formatJSON {
+		if rates == nil {
+			rates = []parser.CentralBankRate{}
+		}
+		return writeJSON(w, rates)
+	}
+	rows := make([][]string, 0, len(rates))
+	for _, r := range rates {
+		rows = append(rows, []string{
+			r.Bank, r.RateName, r.CurrentRate, r.PreviousRate, r.LastChange,
+			r.HistoryHigh, r.HistoryLow, r.NextForecast, r.LatestCPI,
+		})
+	}
+	return writeRows(w, format, rateColumns, rows)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// 以表格或CSV输出
+func writeRows(w io.Writer, format string, header []string, rows [][]string) error {
+	if format == formatCSV {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		return writer.WriteAll(rows)
+	}
+
+	// 按显示宽度对齐，中文字符占两列
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, cell := range row {
+			if width := runewidth.StringWidth(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+	for _, row := range append([][]string{header}, rows...) {
+		var line strings.Builder
+		for i, cell := range row {
+			line.WriteString(cell)
+			if i < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-runewidth.StringWidth(cell)+2))
+			}
+		}
+		if _, err := fmt.Fprintln(w, line.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher/htmlfetchertest"
+	"github.com/yourusername/fmcl/pkg/server"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+func TestWriteEvents(t *testing.T) {
+	day := time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC)
+	snapshot := fetchFixture(t, day)
+	events := filterImportance(snapshot.Events, "高")
+	if len(events) == 0 || len(events) == len(snapshot.Events) {
+		t.Fatalf("过滤后 %d 个事件，共 %d 个", len(events), len(snapshot.Events))
+	}
+	loc := time.FixedZone("CST", 8*60*60)
+
+	var table strings.Builder
+	if err := writeEvents(&table, formatTable, events, loc, snapshot.Date); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	if len(lines) != len(events)+1 || !strings.HasPrefix(lines[0], "时间") {
+		t.Fatalf("表格:\n%s", table.String())
+	}
+	// 指标列按显示宽度对齐，中文地区占两列
+	column := runewidth.StringWidth(lines[0][:strings.Index(lines[0], "指标")])
+	for i, line := range lines[1:] {
+		if width := runewidth.StringWidth(line[:strings.Index(line, events[i].Indicator)]); width != column {
+			t.Errorf("第 %d 行未对齐 (%d != %d): %q", i+1, width, column, line)
+		}
+	}
+	if !strings.Contains(table.String(), "1月ADP就业人数(万)") || strings.Contains(table.String(), "12月贸易帐(亿美元)") {
+		t.Errorf("表格内容不正确:\n%s", table.String())
+	}
+
+	var out strings.Builder
+	if err := writeEvents(&out, formatJSON, events, loc, snapshot.Date); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal([]byte(out.String()), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(events) || decoded[0]["indicator"] != events[0].Indicator || decoded[0]["importance"] != "高" {
+		t.Errorf("JSON: %s", out.String())
+	}
+
+	out.Reset()
+	if err := writeEvents(&out, formatCSV, events, loc, snapshot.Date); err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != len(events)+1 || strings.Join(records[0], ",") != strings.Join(eventColumns, ",") {
+		t.Errorf("CSV: %q", records)
+	}
+}
+
+func TestWriteEmptyJSON(t *testing.T) {
+	var out strings.Builder
+	if err := writeEvents(&out, formatJSON, filterImportance(nil, ""), time.UTC, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeRates(&out, formatJSON, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "[]\n[]\n" {
+		t.Errorf("没有数据时应输出空数组: %q", out.String())
+	}
+}
+
+// 写入总是失败的输出
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("磁盘已满")
+}
+
+func TestWriteRowsReturnsWriteError(t *testing.T) {
+	for _, format := range []string{"table", "csv"} {
+		err := writeRows(failingWriter{}, format, eventColumns, [][]string{{"21:15", "美国"}})
+		if err == nil || err.Error() != "磁盘已满" {
+			t.Errorf("%s 格式应返回写入错误: %v", format, err)
+		}
+	}
+}
+
+func TestCheckFormat(t *testing.T) {
+	for _, format := range []string{"table", "json", "csv"} {
+		if err := checkFormat(format); err != nil {
+			t.Error(err)
+		}
+	}
+	if err := checkFormat("xml"); err == nil {
+		t.Error("未知的格式应返回错误")
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	db, err := storage.NewDB(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	saveSnapshot(db, snapshot)
+	// 上个月的同一指标
+	earlier := source.Snapshot{Date: time.Date(2025, 1, 8, 0, 0, 0, 0, snapshot.Date.Location())}
+	for _, e := range snapshot.Events {
+		if strings.Contains(e.Indicator, "ADP就业人数") {
+			e.Indicator = strings.Replace(e.Indicator, "1月", "12月", 1)
+			e.At = e.At.AddDate(0, 0, -28)
+			earlier.Events = append(earlier.Events, e)
+		}
+	}
+	saveSnapshot(db, earlier)
+
+	events, err := db.History(storage.HistoryQuery{Indicator: regexp.MustCompile(`ADP就业人数`), Region: "美国"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Date != "2025-02-05" {
+		t.Fatalf("历史数据: %+v", events)
+	}
+
+	var out strings.Builder
+	if err := writeHistory(&out, formatTable, events, time.FixedZone("CST", 8*60*60)); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "日期") || !strings.HasPrefix(lines[1], "2025-02-05  21:15") {
+		t.Errorf("表格:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := writeHistory(&out, formatJSON, nil, time.UTC); err != nil || strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("没有数据时应输出空数组: %q %v", out.String(), err)
+	}
+}
+
+func TestFetchUpcomingStoresCalendarDays(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("..", "..", "testdata", "fx678", "20250205.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	today := time.Date(2025, 2, 5, 0, 0, 0, 0, time.FixedZone("CST", 8*60*60))
+	for i := 1; i < server.DefaultCalendarDays; i++ {
+		name := today.AddDate(0, 0, i).Format("20060102") + ".html"
+		if err := os.WriteFile(filepath.Join(dir, name), page, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pages := htmlfetchertest.NewServer(dir)
+	t.Cleanup(pages.Close)
+	src := source.NewFX678(&htmlfetcher.DefaultFetcher{})
+	src.BaseURL = pages.URL
+
+	db, err := storage.NewDB(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err := fetchUpcoming(src, db, today); err != nil {
+		t.Fatal(err)
+	}
+	if len(pages.Requests()) != server.DefaultCalendarDays-1 {
+		t.Errorf("请求 = %v", pages.Requests())
+	}
+	// 当天由每次刷新获取，订阅日历默认范围内的其余日期都应保存
+	for i := 0; i < server.DefaultCalendarDays; i++ {
+		date := today.AddDate(0, 0, i).Format("2006-01-02")
+		if ok, err := db.HasDate(date); err != nil || ok != (i > 0) {
+			t.Errorf("%s 已保存 = %v, %v", date, ok, err)
+		}
+	}
+}
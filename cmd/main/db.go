@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// 执行 db 子命令: fmcl db migrate | fmcl db status，databasePath 为数据库文件
+func runDB(args []string, databasePath string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: fmcl db <migrate|status>")
+	}
+
+	switch args[0] {
+	case "migrate":
+		db, err := storage.Open(databasePath)
+		if err != nil {
+			return fmt.Errorf("打开数据库失败: %v", err)
+		}
+		defer db.Close()
+
+		applied, err := db.Migrate()
+		for _, m := range applied {
+			fmt.Printf("已应用迁移 %s\n", m.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		version, err := db.SchemaVersion()
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Printf("数据库已是最新版本 (%d)\n", version)
+		} else {
+			fmt.Printf("数据库已升级到版本 %d\n", version)
+		}
+		return nil
+	case "status":
+		db, err := storage.Open(databasePath)
+		if err != nil {
+			return fmt.Errorf("打开数据库失败: %v", err)
+		}
+		defer db.Close()
+
+		status, err := db.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("数据库: %s\n", databasePath)
+		pending := 0
+		for _, s := range status {
+			if s.Applied {
+				fmt.Printf("  [已应用] %s  %s\n", s.Name, s.AppliedAt.Format("2006-01-02 15:04:05"))
+			} else {
+				pending++
+				fmt.Printf("  [待应用] %s\n", s.Name)
+			}
+		}
+		fmt.Printf("待应用迁移: %d\n", pending)
+		return nil
+	default:
+		return fmt.Errorf("未知的 db 子命令: %s", args[0])
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/export"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/surprise"
+	"github.com/yourusername/fmcl/pkg/watchlist"
+)
+
+// JSON Lines 中的一行，kind 区分财经日历事件、重要事件和央行利率
+type eventRecord struct {
+	Kind string `json:"kind"`
+	Date string `json:"date"`
+	parser.CalendarEvent
+	Surprise *surprise.Surprise `json:"surprise,omitempty"`
+}
+
+type importantEventRecord struct {
+	Kind string `json:"kind"`
+	Date string `json:"date"`
+	parser.ImportantEvent
+}
+
+type rateRecord struct {
+	Kind string `json:"kind"`
+	Date string `json:"date"`
+	parser.CentralBankRate
+}
+
+// 按显示模式生成导出的表，包含的行与界面一致，表头与 list、events、rates 命令相同，
+// 另加日期列和意外列。一周视图导出整周的高重要性事件，collapsed 中已折叠的日期除外
+func exportSheets(snapshots []source.Snapshot, anns []annotations, mode DisplayMode, collapsed map[string]bool, loc *time.Location) []export.Sheet {
+	events := export.Sheet{Name: "财经日历事件", Header: append(append([]string{"日期"}, eventColumns...), "意外")}
+	important := export.Sheet{Name: "重要事件", Header: append([]string{"日期"}, importantEventColumns...)}
+	rates := export.Sheet{Name: "央行利率", Header: append([]string{"日期"}, rateColumns...)}
+
+	for i, snapshot := range snapshots {
+		date := dayKey(snapshot.Date)
+		if mode == ModeWeek && collapsed[date] {
+			continue
+		}
+		for _, e := range snapshot.Events {
+			if !visibleEvent(mode, e, anns[i]) {
+				continue
+			}
+			s := anns[i].surprises[eventKey(e.Time, e.Region, e.Indicator)]
+			surpriseText := ""
+			if s != nil {
+				surpriseText = s.String()
+			}
+			events.Rows = append(events.Rows, []string{
+				date, displayTime(e.Time, e.At, snapshot.Date, loc), e.Region, e.Indicator,
+				e.Previous, e.Forecast, e.Actual, e.Importance, e.Impact, surpriseText,
+			})
+			events.Records = append(events.Records, eventRecord{"event", date, e, s})
+		}
+
+		if showsImportantEvents(mode) {
+			for _, e := range snapshot.ImportantEvents {
+				if !visibleImportantEvent(mode, e, anns[i]) {
+					continue
+				}
+				important.Rows = append(important.Rows, []string{
+					date, displayTime(e.Time, e.At, snapshot.Date, loc), e.Region, e.Location, e.Importance, e.Event,
+				})
+				important.Records = append(important.Records, importantEventRecord{"important_event", date, e})
+			}
+		}
+
+		if showsRates(mode) {
+			for _, r := range snapshot.Rates {
+				rates.Rows = append(rates.Rows, []string{
+					date, r.Bank, r.RateName, r.CurrentRate, r.PreviousRate, r.LastChange,
+					r.HistoryHigh, r.HistoryLow, r.NextForecast, r.LatestCPI,
+				})
+				rates.Records = append(rates.Records, rateRecord{"rate", date, r})
+			}
+		}
+	}
+
+	sheets := []export.Sheet{events}
+	if showsImportantEvents(mode) {
+		sheets = append(sheets, important)
+	}
+	if showsRates(mode) {
+		sheets = append(sheets, rates)
+	}
+	return sheets
+}
+
+// 导出文件名，例如 fmcl-20250205-all-153012.csv
+func exportFileName(day time.Time, mode DisplayMode, format string, now time.Time) string {
+	if mode == ModeWeek {
+		day = startOfWeek(day)
+	}
+	return fmt.Sprintf("fmcl-%s-%s-%s.%s", day.Format("20060102"), config.DisplayModeNames[mode], now.Format("150405"), format)
+}
+
+// 将 sheets 写入 path，必要时创建目录
+func writeExportFile(path string, sheets []export.Sheet, opts export.Options) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("创建导出目录失败: %v", err)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建导出文件失败: %v", err)
+	}
+	if err := export.Write(file, sheets, opts); err != nil {
+		file.Close()
+		return fmt.Errorf("写入导出文件失败: %v", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("写入导出文件失败: %v", err)
+	}
+	return nil
+}
+
+// 注册 export 命令的参数。显示模式取全局参数 --mode（编号或名称，见 config.BindFlags）或配置中的默认显示模式
+func exportCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	date := fs.String("date", "", "日期 YYYY-MM-DD 或 YYYYMMDD，默认今天（数据源时区）")
+	format := fs.String("format", "", "导出格式: csv jsonl xlsx，默认取配置 export.format")
+	bom := fs.Bool("bom", false, "CSV 开头写入 UTF-8 BOM，默认取配置 export.bom")
+	output := fs.String("output", "", "写入的文件，默认输出到标准输出")
+	return func(c *cliContext) error {
+		opts := export.Options{Format: c.cfg.Export.Format, BOM: c.cfg.Export.BOM}
+		if *format != "" {
+			var err error
+			if opts.Format, err = export.ParseFormat(*format); err != nil {
+				return err
+			}
+		}
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "bom" {
+				opts.BOM = *bom
+			}
+		})
+
+		sheets, err := cliExportSheets(c, *date)
+		if err != nil {
+			return err
+		}
+		if *output == "" {
+			return export.Write(c.out, sheets, opts)
+		}
+		if err := writeExportFile(*output, sheets, opts); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "已导出到 %s\n", *output)
+		return nil
+	}
+}
+
+// 按与界面相同的方式获取数据并生成导出的表
+func cliExportSheets(c *cliContext, date string) ([]export.Sheet, error) {
+	src, db, err := openData(c.cfg, &htmlfetcher.DefaultFetcher{})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	day, err := cliDate(date, src.Location())
+	if err != nil {
+		return nil, err
+	}
+	mode := DisplayMode(c.cfg.DefaultDisplayMode)
+	var snapshots []source.Snapshot
+	if mode == ModeWeek {
+		snapshots, err = loadWeek(src, db, startOfWeek(day))
+	} else {
+		var snapshot source.Snapshot
+		snapshot, err = loadDay(src, db, day, startOfDay(time.Now(), src.Location()), false)
+		snapshots = []source.Snapshot{snapshot}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("获取数据失败: %v", err)
+	}
+
+	wl, err := watchlist.Load(c.cfg.Watchlist)
+	if err != nil {
+		return nil, fmt.Errorf("加载关注列表失败: %v", err)
+	}
+	anns := make([]annotations, len(snapshots))
+	for i, snapshot := range snapshots {
+		anns[i] = loadAnnotations(db, snapshot)
+		markWatched(&anns[i], snapshot, wl)
+	}
+	return exportSheets(snapshots, anns, mode, nil, c.cfg.Location()), nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/export"
+	"github.com/yourusername/fmcl/pkg/source"
+)
+
+func TestExportSheets(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	snapshots := []source.Snapshot{snapshot}
+	anns := []annotations{loadAnnotations(nil, snapshot)}
+	loc := time.FixedZone("CST", 8*60*60)
+
+	sheets := exportSheets(snapshots, anns, ModeHighImportance, nil, loc)
+	if len(sheets) != 1 || sheets[0].Header[0] != "日期" || sheets[0].Header[len(sheets[0].Header)-1] != "意外" {
+		t.Fatalf("高重要性模式只导出财经日历事件: %+v", sheets)
+	}
+	if len(sheets[0].Rows) == 0 || len(sheets[0].Records) != len(sheets[0].Rows) {
+		t.Fatalf("记录数 %d, 行数 %d", len(sheets[0].Records), len(sheets[0].Rows))
+	}
+	for _, row := range sheets[0].Rows {
+		if row[0] != "2025-02-05" || row[7] != "高" {
+			t.Errorf("高重要性模式的行: %q", row)
+		}
+	}
+
+	sheets = exportSheets(snapshots, anns, ModeAll, nil, loc)
+	var names []string
+	for _, sheet := range sheets {
+		names = append(names, sheet.Name)
+	}
+	if strings.Join(names, ",") != "财经日历事件,重要事件,央行利率" || len(sheets[0].Rows) != len(snapshot.Events) {
+		t.Fatalf("全部模式: %q, %d 行", names, len(sheets[0].Rows))
+	}
+	if len(sheets[2].Rows) != len(snapshot.Rates) || sheets[2].Header[1] != "央行" {
+		t.Errorf("央行利率: %q", sheets[2].Header)
+	}
+	// 意外值与界面一致
+	for _, row := range sheets[0].Rows {
+		if row[3] == "1月ADP就业人数(万)" && row[9] == "" {
+			t.Errorf("缺少意外值: %q", row)
+		}
+	}
+}
+
+func TestExportSheetsSkipsCollapsedDays(t *testing.T) {
+	snapshots := []source.Snapshot{
+		fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC)),
+		fetchFixture(t, time.Date(2025, 2, 7, 12, 0, 0, 0, time.UTC)),
+	}
+	anns := []annotations{loadAnnotations(nil, snapshots[0]), loadAnnotations(nil, snapshots[1])}
+	collapsed := map[string]bool{"2025-02-05": true}
+	loc := time.FixedZone("CST", 8*60*60)
+
+	sheets := exportSheets(snapshots, anns, ModeWeek, collapsed, loc)
+	if len(sheets) != 1 || len(sheets[0].Rows) == 0 {
+		t.Fatalf("一周视图: %+v", sheets)
+	}
+	for _, row := range sheets[0].Rows {
+		if row[0] != "2025-02-07" {
+			t.Errorf("已折叠的日期不应导出: %q", row)
+		}
+	}
+
+	// 其他视图不受折叠影响
+	sheets = exportSheets(snapshots[:1], anns[:1], ModeHighImportance, collapsed, loc)
+	if len(sheets[0].Rows) == 0 {
+		t.Error("单日视图应忽略折叠状态")
+	}
+}
+
+func TestWriteExportFile(t *testing.T) {
+	day := time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 2, 5, 15, 30, 12, 0, time.UTC)
+	if name := exportFileName(day, ModeWeek, export.FormatXLSX, now); name != "fmcl-20250203-week-153012.xlsx" {
+		t.Errorf("一周视图的文件名 = %s", name)
+	}
+
+	path := filepath.Join(t.TempDir(), "exports", exportFileName(day, ModeAll, export.FormatCSV, now))
+	sheets := []export.Sheet{{Name: "财经日历事件", Header: eventColumns}}
+	if err := writeExportFile(path, sheets, export.Options{Format: export.FormatCSV, BOM: true}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(content), "\ufeff时间,地区,指标") {
+		t.Errorf("导出内容: %q", content)
+	}
+}
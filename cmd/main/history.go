@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gizak/termui/v3"
+	"github.com/gizak/termui/v3/widgets"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// 界面中历史弹窗显示的最多条数
+const historyPopupLimit = 24
+
+// 注册 history 命令的参数
+func historyCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	indicator := fs.String("indicator", "", "指标名称的正则表达式，例如 'CPI年率$'")
+	region := fs.String("region", "", "地区，例如 美国")
+	since := fs.String("since", "", "起始日期 YYYY-MM-DD 或 YYYYMMDD")
+	until := fs.String("until", "", "截止日期 YYYY-MM-DD 或 YYYYMMDD")
+	limit := fs.Int("limit", 0, "最多输出最近的多少条，0 表示不限")
+	format := fs.String("format", formatTable, "输出格式: table json csv")
+	return func(c *cliContext) error {
+		if err := checkFormat(*format); err != nil {
+			return err
+		}
+		if *limit < 0 {
+			return fmt.Errorf("条数不能为负数: %d", *limit)
+		}
+		q := storage.HistoryQuery{Region: strings.TrimSpace(*region), Limit: *limit}
+		if *indicator != "" {
+			re, err := regexp.Compile(*indicator)
+			if err != nil {
+				return fmt.Errorf("无效的指标正则表达式: %v", err)
+			}
+			q.Indicator = re
+		}
+		for _, d := range []struct {
+			input  string
+			target *string
+		}{{*since, &q.Since}, {*until, &q.Until}} {
+			if d.input == "" {
+				continue
+			}
+			day, err := parseDateInput(d.input, time.UTC)
+			if err != nil {
+				return err
+			}
+			*d.target = day.Format("2006-01-02")
+		}
+
+		db, err := storage.NewDB(c.cfg.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("初始化数据库失败: %v", err)
+		}
+		defer db.Close()
+		events, err := db.History(q)
+		if err != nil {
+			return fmt.Errorf("查询历史数据失败: %v", err)
+		}
+		return writeHistory(c.out, *format, events, c.cfg.Location())
+	}
+}
+
+// 历史数据的表头
+var historyColumns = []string{"日期", "时间", "地区", "指标", "前值", "预测", "公布值", "意外"}
+
+// 输出历史数据，从新到旧。表格和CSV中的时间按显示时区 loc 换算
+func writeHistory(w io.Writer, format string, events []storage.StoredEvent, loc *time.Location) error {
+	if format == formatJSON {
+		if events == nil {
+			events = []storage.StoredEvent{}
+		}
+		return writeJSON(w, events)
+	}
+	rows := make([][]string, 0, len(events))
+	for _, e := range events {
+		day, _ := time.Parse("2006-01-02", e.Date)
+		surpriseText := ""
+		if e.Surprise != nil {
+			surpriseText = e.Surprise.String()
+		}
+		rows = append(rows, []string{
+			e.Date, displayTime(e.Time, e.At, day, loc), e.Region, e.Indicator,
+			e.Previous, e.Forecast, e.Actual, surpriseText,
+		})
+	}
+	return writeRows(w, format, historyColumns, rows)
+}
+
+// 生成光标所在财经日历事件的历史弹窗，与 fmcl history 按地区和指标查询的结果相同
+func historyPopup(db *storage.DB, event *parser.CalendarEvent, loc *time.Location) (*widgets.List, error) {
+	series := parser.SeriesName(event.Indicator)
+	events, err := db.History(storage.HistoryQuery{Series: series, Region: event.Region, Limit: historyPopupLimit})
+	if err != nil {
+		return nil, fmt.Errorf("查询历史数据失败: %v", err)
+	}
+
+	var table strings.Builder
+	if err := writeHistory(&table, formatTable, events, loc); err != nil {
+		return nil, err
+	}
+	popup := widgets.NewList()
+	popup.Title = fmt.Sprintf("历史: %s %s (最近%d条, ESC关闭)", event.Region, series, len(events))
+	for i, line := range strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n") {
+		if i == 0 {
+			line = fmt.Sprintf("[%s](fg:cyan)", line)
+		}
+		popup.Rows = append(popup.Rows, line)
+	}
+	if len(events) == 0 {
+		popup.Rows = append(popup.Rows, "[数据库中没有该指标的历史数据](fg:yellow)")
+	}
+	popup.TextStyle.Fg = termui.ColorWhite
+	popup.BorderStyle.Fg = termui.ColorCyan
+	popup.TitleStyle.Fg = termui.ColorGreen
+	popup.WrapText = false
+
+	// 居中显示，留出边距
+	termWidth, termHeight := termui.TerminalDimensions()
+	width, height := termWidth-8, len(popup.Rows)+2
+	if height > termHeight-4 {
+		height = termHeight - 4
+	}
+	x, y := (termWidth-width)/2, (termHeight-height)/2
+	popup.SetRect(x, y, x+width, y+height)
+	return popup, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/ical"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// 注册 ics 命令的参数
+func icsCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	date := fs.String("date", "", "起始日期 YYYY-MM-DD 或 YYYYMMDD，默认今天（数据源时区）")
+	days := fs.Int("days", 7, "导出的天数")
+	importance := fs.String("importance", "all", "重要性: high medium low all（也可用 高 中 低）")
+	output := fs.String("output", "", "写入的文件，默认输出到标准输出")
+	return func(c *cliContext) error {
+		if *days <= 0 {
+			return fmt.Errorf("天数必须大于0: %d", *days)
+		}
+		level, err := parser.ParseImportance(*importance)
+		if err != nil {
+			return err
+		}
+		cal, err := exportCalendar(c, *date, *days, level)
+		if err != nil {
+			return err
+		}
+
+		if *output == "" {
+			_, err = cal.WriteTo(c.out)
+			return err
+		}
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("创建文件失败: %v", err)
+		}
+		if _, err := cal.WriteTo(file); err != nil {
+			file.Close()
+			return fmt.Errorf("写入文件失败: %v", err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("写入文件失败: %v", err)
+		}
+		fmt.Fprintf(os.Stderr, "已导出 %d 个事件到 %s\n", cal.Len(), *output)
+		return nil
+	}
+}
+
+// 按天获取数据（过去的日期优先使用本地存储），生成日历
+func exportCalendar(c *cliContext, date string, days int, level string) (*ical.Calendar, error) {
+	src, db, err := openData(c.cfg, &htmlfetcher.DefaultFetcher{})
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	from, err := cliDate(date, src.Location())
+	if err != nil {
+		return nil, err
+	}
+	today := startOfDay(time.Now(), src.Location())
+	cal := ical.New("财经日历", src.Location())
+	for i := 0; i < days; i++ {
+		snapshot, err := loadDay(src, db, from.AddDate(0, 0, i), today, false)
+		if err != nil {
+			return nil, fmt.Errorf("获取数据失败: %v", err)
+		}
+		day := snapshot.Date.Format("2006-01-02")
+		cal.AddEvents(day, filterImportance(snapshot.Events, level))
+
+		var important []parser.ImportantEvent
+		for _, e := range snapshot.ImportantEvents {
+			if level == "" || e.Importance == level {
+				important = append(important, e)
+			}
+		}
+		cal.AddImportantEvents(day, important)
+	}
+	return cal, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -14,11 +15,15 @@ import (
 	"github.com/gizak/termui/v3"
 	"github.com/gizak/termui/v3/widgets"
 	"go.uber.org/zap"
-	"gopkg.in/yaml.v3"
 
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/export"
 	"github.com/yourusername/fmcl/pkg/htmlfetcher"
 	"github.com/yourusername/fmcl/pkg/logger"
 	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+	"github.com/yourusername/fmcl/pkg/watchlist"
 )
 
 // 格式化字符串宽度
@@ -54,6 +59,8 @@ const (
 	ModeAll
 	ModeWithRates
 	ModeWithImportant
+	ModeWeek
+	ModeWatchlist
 )
 
 // 应用状态
@@ -64,6 +71,152 @@ type AppState struct {
 	mu              sync.Mutex
 	nextRefreshTime time.Time
 	startTime       time.Time
+	replaying       bool
+	layoutChanged   bool
+	clock           func() time.Time
+	replayDone      func() bool    // 回放是否已越过最后一个快照
+	sourceLocation  *time.Location // 数据源时区，用于计算"今天"
+	viewDate        time.Time      // 正在查看的日期，零值表示今天
+	inputMode       bool           // 是否正在输入跳转日期
+	inputBuffer     string
+	message         string          // 状态栏提示
+	collapsedDays   map[string]bool // 一周视图中已折叠的日期
+}
+
+// 数据源时区下的今天
+func (s *AppState) today() time.Time {
+	loc := s.sourceLocation
+	if loc == nil {
+		loc = time.Local
+	}
+	return startOfDay(s.now(), loc)
+}
+
+// 正在查看的日期
+func (s *AppState) currentDay() time.Time {
+	if s.viewDate.IsZero() {
+		return s.today()
+	}
+	return s.viewDate
+}
+
+// 是否正在查看今天，只有今天会自动刷新
+func (s *AppState) viewingToday() bool {
+	return s.viewDate.IsZero() || s.viewDate.Equal(s.today())
+}
+
+// 当前视图是否包含今天，只有包含今天的视图会自动刷新
+func (s *AppState) autoRefresh() bool {
+	if s.displayMode == ModeWeek {
+		return startOfWeek(s.currentDay()).Equal(startOfWeek(s.today()))
+	}
+	return s.viewingToday()
+}
+
+// 折叠或展开一周视图中的某一天，调用方需持有锁
+func (s *AppState) toggleDay(day time.Time) {
+	if s.collapsedDays == nil {
+		s.collapsedDays = make(map[string]bool)
+	}
+	key := dayKey(day)
+	s.collapsedDays[key] = !s.collapsedDays[key]
+}
+
+// 切换到指定日期，调用方需持有锁
+func (s *AppState) setDay(day time.Time) {
+	if day.Equal(s.today()) {
+		s.viewDate = time.Time{}
+		return
+	}
+	s.viewDate = day
+}
+
+// 应用重新加载的配置并返回状态栏提示，调用方需持有锁。
+// 默认显示模式改变时切换到新的模式
+func (s *AppState) applyConfig(cfg, next *config.AppConfig) string {
+	if next.DefaultDisplayMode != cfg.DefaultDisplayMode {
+		s.displayMode = DisplayMode(next.DefaultDisplayMode)
+	}
+	message := "配置已重新加载"
+	if keys := cfg.Apply(next); len(keys) > 0 {
+		message += fmt.Sprintf("，%s 需重启后生效", strings.Join(keys, "、"))
+	}
+	return message
+}
+
+// 将多行的配置错误合并为一行，用于状态栏
+func configErrorText(err error) string {
+	var lines []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "- ")
+		if line != "" && line != "配置无效:" {
+			lines = append(lines, line)
+		}
+	}
+	return "配置未生效: " + strings.Join(lines, "; ")
+}
+
+// 当前时间，回放模式下为回放的虚拟时间
+func (s *AppState) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+// 显示模式名称
+var modeNames = map[DisplayMode]string{
+	ModeHighImportance: "仅显示高重要性",
+	ModeAll:            "显示所有数据",
+	ModeWithRates:      "显示高重要性+利率信息",
+	ModeWithImportant:  "显示高重要性+重要事件",
+	ModeWeek:           "一周高重要性",
+	ModeWatchlist:      "仅显示关注",
+}
+
+// 状态栏文本，调用方需持有锁
+func (s *AppState) statusText() string {
+	if s.inputMode {
+		return fmt.Sprintf("跳转到日期 (YYYYMMDD, Enter确认, ESC取消): %s_", s.inputBuffer)
+	}
+
+	refresh := formatCountdown(s.nextRefreshTime)
+	if !s.autoRefresh() {
+		refresh = "仅今天自动刷新"
+		if s.displayMode == ModeWeek {
+			refresh = "仅本周自动刷新"
+		}
+	}
+	text := fmt.Sprintf("模式: %s | 状态: %s | 下次刷新: %s",
+		modeNames[s.displayMode],
+		map[bool]string{true: "已暂停", false: "运行中"}[s.isPaused],
+		refresh)
+	if s.message != "" {
+		text += " | " + s.message
+	}
+	if s.layoutChanged {
+		text += " | [数据源页面结构已变化](fg:red)"
+	}
+	return text
+}
+
+// 标题栏文本
+func (s *AppState) headerText() string {
+	day := s.currentDay()
+	dayText := fmt.Sprintf("%s %s", day.Format("2006-01-02"), weekdayNames[day.Weekday()])
+	if s.displayMode == ModeWeek {
+		monday := startOfWeek(day)
+		dayText = fmt.Sprintf("%s ~ %s", monday.Format("2006-01-02"), monday.AddDate(0, 0, 6).Format("2006-01-02"))
+		if s.autoRefresh() {
+			dayText += " (本周)"
+		}
+	} else if s.viewingToday() {
+		dayText += " (今天)"
+	}
+	if s.replaying {
+		return fmt.Sprintf("FMCL 回放 @ %s | 日期: %s", s.now().Format("2006-01-02 15:04:05"), dayText)
+	}
+	return fmt.Sprintf("FMCL @ %s | 日期: %s", s.startTime.Format("2006-01-02 15:04:05"), dayText)
 }
 
 func (s *AppState) togglePause() {
@@ -75,7 +228,7 @@ func (s *AppState) togglePause() {
 func (s *AppState) nextMode() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.displayMode = (s.displayMode + 1) % 4
+	s.displayMode = (s.displayMode + 1) % DisplayMode(len(modeNames))
 }
 
 // 显示帮助信息
@@ -87,8 +240,18 @@ q: 退出程序
 r: 强制刷新
 p: 暂停/继续刷新
 m: 切换显示模式
+←/[: 前一天    →/]: 后一天 (一周视图按周)
+t: 回到今天    g: 跳转到指定日期
+↑/k ↓/j: 移动光标
+Enter/空格: 查看历史数据 (一周视图为折叠/展开日期)
+i: 查看光标所在事件的历史数据
+w: 关注/取消关注光标所在事件
+e: 导出当前视图到文件
 h: 显示/隐藏帮助
-ESC: 关闭此帮助
+ESC: 关闭帮助或历史弹窗
+*: 数值已被修正    ★: 关注的事件
+意外: 公布值-预测值 (σ为历史标准化分数)
+      红色利多 / 绿色利空
 `
 	help.BorderStyle.Fg = termui.ColorCyan
 	help.TitleStyle.Fg = termui.ColorGreen
@@ -96,8 +259,8 @@ ESC: 关闭此帮助
 
 	// 居中显示帮助菜单
 	termWidth, termHeight := termui.TerminalDimensions()
-	helpWidth := 40
-	helpHeight := 10
+	helpWidth := 50
+	helpHeight := 19
 	x := (termWidth - helpWidth) / 2
 	y := (termHeight - helpHeight) / 2
 	help.SetRect(x, y, x+helpWidth, y+helpHeight)
@@ -130,54 +293,32 @@ func formatCountdown(nextRefresh time.Time) string {
 	return fmt.Sprintf("%02d:%02d", int(duration.Minutes()), int(duration.Seconds())%60)
 }
 
-// Config 配置结构
-type Config struct {
-	RefreshInterval    int `yaml:"refresh_interval"`
-	DefaultDisplayMode int `yaml:"default_display_mode"`
-	UI                 struct {
-		TimeWidth       int `yaml:"time_width"`
-		ImportanceWidth int `yaml:"importance_width"`
-		ValueWidth      int `yaml:"value_width"`
-	} `yaml:"ui"`
-}
-
-// 加载配置
-func loadConfig() (*Config, error) {
-	// 默认配置
-	config := &Config{
-		RefreshInterval:    15,
-		DefaultDisplayMode: 0,
-		UI: struct {
-			TimeWidth       int `yaml:"time_width"`
-			ImportanceWidth int `yaml:"importance_width"`
-			ValueWidth      int `yaml:"value_width"`
-		}{
-			TimeWidth:       6,
-			ImportanceWidth: 6,
-			ValueWidth:      12,
-		},
-	}
-
-	// 尝试读取配置文件
-	configPath := filepath.Join(".", "config.yaml")
-	data, err := os.ReadFile(configPath)
+// 保存本次刷新解析到的数据
+func saveSnapshot(db *storage.DB, snapshot source.Snapshot) {
+	if db == nil {
+		return
+	}
+	date := snapshot.Date.Format("2006-01-02")
+	revisions, err := db.SaveCalendarEvents(date, snapshot.Events)
 	if err != nil {
-		if !os.IsNotExist(err) {
-			return nil, fmt.Errorf("读取配置文件失败: %v", err)
-		}
-		// 如果配置文件不存在，使用默认配置
-		return config, nil
+		logger.Error("保存财经日历事件失败", zap.Error(err))
 	}
-
-	// 解析配置文件
-	if err := yaml.Unmarshal(data, config); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+	for _, r := range revisions {
+		logger.Info("数据变化",
+			zap.String("indicator", r.Indicator),
+			zap.String("field", r.Field),
+			zap.String("old", r.OldValue),
+			zap.String("new", r.NewValue))
+	}
+	if err := db.SaveImportantEvents(date, snapshot.ImportantEvents); err != nil {
+		logger.Error("保存重要事件失败", zap.Error(err))
+	}
+	if err := db.SaveCentralBankRates(date, snapshot.Rates); err != nil {
+		logger.Error("保存央行利率失败", zap.Error(err))
 	}
-
-	return config, nil
 }
 
-func displayData(fetcher *htmlfetcher.DefaultFetcher, state *AppState, config *Config) {
+func displayData(src source.Source, db *storage.DB, alerts *alerter, wl *watchlist.Watchlist, state *AppState, cfg *config.AppConfig, reloader *config.Reloader) {
 	if err := termui.Init(); err != nil {
 		logger.Error("初始化TUI失败", zap.Error(err))
 		return
@@ -186,7 +327,7 @@ func displayData(fetcher *htmlfetcher.DefaultFetcher, state *AppState, config *C
 
 	// 创建TUI组件
 	header := widgets.NewParagraph()
-	header.Text = fmt.Sprintf("FMCL @ %s", state.startTime.Format("2006-01-02 15:04:05"))
+	header.Text = state.headerText()
 	header.TextStyle.Fg = termui.ColorGreen
 	header.Border = false
 
@@ -194,6 +335,7 @@ func displayData(fetcher *htmlfetcher.DefaultFetcher, state *AppState, config *C
 	dataList.TextStyle.Fg = termui.ColorWhite
 	dataList.BorderStyle.Fg = termui.ColorBlue
 	dataList.WrapText = false
+	dataList.SelectedRowStyle = termui.NewStyle(termui.ColorBlack, termui.ColorCyan)
 
 	statusBar := widgets.NewParagraph()
 	statusBar.TextStyle.Fg = termui.ColorGreen
@@ -221,199 +363,124 @@ func displayData(fetcher *htmlfetcher.DefaultFetcher, state *AppState, config *C
 	// 创建帮助菜单（初始不显示）
 	helpMenu := showHelpMenu()
 	showingHelp := false
+	var historyList *widgets.List // 光标所在事件的历史弹窗，nil 表示未打开
 
-	// 创建分隔线
-	createSeparator := func() string {
-		return strings.Repeat("─", termWidth-2)
+	// 渲染全部组件，帮助菜单和历史弹窗在最上层
+	render := func() {
+		items := []termui.Drawable{header, dataList, statusBar}
+		if historyList != nil {
+			items = append(items, historyList)
+		}
+		if showingHelp {
+			items = append(items, helpMenu)
+		}
+		termui.Render(items...)
 	}
 
-	updateUI := func() {
+	lastWarnings := ""
+	var refs map[int]rowRef // 列表行对应的日期和事件
+	var snapshots []source.Snapshot
+	var anns []annotations
+
+	// 用最近一次获取的数据重新渲染列表，调用方需持有锁
+	renderData := func() {
+		// 根据显示模式过滤和格式化数据
+		if state.displayMode == ModeWeek {
+			dataList.Rows, refs = renderWeekRows(snapshots, anns, state.collapsedDays, state.today(), cfg, termWidth)
+		} else {
+			dataList.Rows, refs = renderRows(snapshots[0], anns[0], state.displayMode, cfg, termWidth)
+		}
+		if dataList.SelectedRow >= len(dataList.Rows) {
+			dataList.SelectedRow = len(dataList.Rows) - 1
+		}
+
+		// 渲染UI
+		render()
+	}
+
+	// 获取数据并更新界面，force 为 true 时过去的日期也从数据源重新获取
+	updateUI := func(force bool) {
 		state.mu.Lock()
 		defer state.mu.Unlock()
 
 		// 更新状态栏
-		modeStr := "仅显示高重要性"
-		switch state.displayMode {
-		case ModeAll:
-			modeStr = "显示所有数据"
-		case ModeWithRates:
-			modeStr = "显示高重要性+利率信息"
-		case ModeWithImportant:
-			modeStr = "显示高重要性+重要事件"
-		}
-
-		statusBar.Text = fmt.Sprintf("%s | %s | 刷新: %s",
-			modeStr,
-			map[bool]string{true: "已暂停", false: "运行中"}[state.isPaused],
-			formatCountdown(state.nextRefreshTime))
+		statusBar.Text = state.statusText()
 
 		// 获取数据并更新显示
-		dateStr := time.Now().Format("20060102")
-		url := fmt.Sprintf("https://rl.fx678.com/date/%s.html", dateStr)
-		html, err := fetcher.Fetch(url)
+		header.Text = state.headerText()
+		var err error
+		if state.displayMode == ModeWeek {
+			snapshots, err = loadWeek(src, db, startOfWeek(state.currentDay()))
+		} else {
+			var snapshot source.Snapshot
+			snapshot, err = loadDay(src, db, state.currentDay(), state.today(), force)
+			snapshots = []source.Snapshot{snapshot}
+		}
 		if err != nil {
 			logger.Error("获取数据失败", zap.Error(err))
-			dataList.Rows = []string{"获取数据失败: " + err.Error()}
-			if showingHelp {
-				termui.Render(header, dataList, statusBar, helpMenu)
-			} else {
-				termui.Render(header, dataList, statusBar)
-			}
+			dataList.Rows = []string{err.Error()}
+			refs = nil
+			render()
 			return
 		}
 
-		// 解析数据
-		events, importantEvents, rates, err := parser.ParseFinancialCalendar(html)
-		if err != nil {
-			logger.Error("解析数据失败", zap.Error(err))
-			dataList.Rows = []string{"解析数据失败: " + err.Error()}
-			if showingHelp {
-				termui.Render(header, dataList, statusBar, helpMenu)
-			} else {
-				termui.Render(header, dataList, statusBar)
-			}
-			return
+		// 检查页面结构，警告变化时写日志
+		state.layoutChanged = false
+		var warnings []parser.Warning
+		for _, snapshot := range snapshots {
+			state.layoutChanged = state.layoutChanged || snapshot.LayoutChanged()
+			warnings = append(warnings, snapshot.Warnings...)
 		}
-
-		// 根据显示模式过滤和格式化数据
-		var rows []string
-		rows = append(rows, "[=== 财经日历事件 ===](fg:green)")
-		rows = append(rows, fmt.Sprintf("[%-*s  %-*s  %-*s  %-*s  %-*s  %s](fg:cyan)",
-			config.UI.TimeWidth, "时间",
-			config.UI.ImportanceWidth, "重要性",
-			config.UI.ValueWidth, "前值",
-			config.UI.ValueWidth, "预测",
-			config.UI.ValueWidth, "公布值",
-			"指标名称"))
-		rows = append(rows, createSeparator())
-
-		currentTime := ""
-		for _, event := range events {
-			if state.displayMode == ModeHighImportance && event.Importance != "高" {
-				continue
-			}
-
-			if event.Time != currentTime {
-				if currentTime != "" {
-					rows = append(rows, createSeparator())
+		warningText := fmt.Sprint(warnings)
+		if warningText != lastWarnings {
+			for _, snapshot := range snapshots {
+				for _, w := range snapshot.Warnings {
+					logger.Warn("数据源页面结构异常",
+						zap.String("source", snapshot.Source),
+						zap.String("kind", string(w.Kind)),
+						zap.String("detail", w.String()))
 				}
-				currentTime = event.Time
 			}
-
-			importanceColor := "white"
-			if event.Importance == "高" {
-				importanceColor = "red"
-			} else if event.Importance == "中" {
-				importanceColor = "yellow"
-			}
-
-			row := fmt.Sprintf("[%-*s](fg:cyan)  [%-*s](fg:%s)  [%-*s](fg:white)  [%-*s](fg:white)  [%-*s](fg:green)  [%s](fg:white)",
-				config.UI.TimeWidth, event.Time,
-				config.UI.ImportanceWidth, event.Importance,
-				importanceColor,
-				config.UI.ValueWidth, formatWidth(event.Previous, config.UI.ValueWidth),
-				config.UI.ValueWidth, formatWidth(event.Forecast, config.UI.ValueWidth),
-				config.UI.ValueWidth, formatWidth(event.Actual, config.UI.ValueWidth),
-				event.Indicator)
-			rows = append(rows, row)
+			lastWarnings = warningText
 		}
+		statusBar.Text = state.statusText()
 
-		if state.displayMode == ModeWithImportant || state.displayMode == ModeAll {
-			if len(importantEvents) > 0 {
-				rows = append(rows, "")
-				rows = append(rows, "[=== 重要事件 ===](fg:green)")
-				rows = append(rows, fmt.Sprintf("[%-*s  %-*s  %s](fg:cyan)",
-					config.UI.TimeWidth, "时间",
-					config.UI.ImportanceWidth, "重要性",
-					"事件"))
-				rows = append(rows, createSeparator())
-
-				for _, event := range importantEvents {
-					if event.Importance == "高" || state.displayMode == ModeAll {
-						importanceColor := "white"
-						if event.Importance == "高" {
-							importanceColor = "red"
-						}
-						row := fmt.Sprintf("[%-*s](fg:cyan)  [%-*s](fg:%s)  [%s](fg:white)",
-							config.UI.TimeWidth, event.Time,
-							config.UI.ImportanceWidth, event.Importance,
-							importanceColor,
-							event.Event)
-						rows = append(rows, row)
-					}
-				}
-			}
+		// 高重要性事件的公布提醒
+		if fired := alerts.check(snapshots, state.today(), state.now()); len(fired) > 0 {
+			state.message = fired[len(fired)-1].Title()
+			statusBar.Text = state.statusText()
 		}
 
-		if state.displayMode == ModeWithRates || state.displayMode == ModeAll {
-			rows = append(rows, "")
-			rows = append(rows, "[=== 央行利率信息 ===](fg:green)")
-			bankWidth := 20
-			rateWidth := 10
-			changeWidth := 8
-			dateWidth := 12
-			historyWidth := 20
-
-			rows = append(rows, fmt.Sprintf("[%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s](fg:cyan)",
-				bankWidth, "央行/利率类型",
-				rateWidth, "当前利率",
-				rateWidth, "前值",
-				changeWidth, "变动",
-				dateWidth, "变动日期",
-				historyWidth, "历史区间",
-				rateWidth, "下次预测"))
-			rows = append(rows, createSeparator())
-
-			for _, rate := range rates {
-				parts := strings.Split(rate.LastChange, " ")
-				changeDate := ""
-				changeValue := ""
-				if len(parts) >= 2 {
-					changeDate = parts[1]
-					changeValue = parts[0]
-				}
-
-				historyRange := fmt.Sprintf("%s - %s", rate.HistoryLow, rate.HistoryHigh)
-				bankInfo := fmt.Sprintf("%s - %s", rate.Bank, rate.RateName)
-
-				row := fmt.Sprintf("[%-*s](fg:yellow)  [%-*s](fg:green)  [%-*s](fg:white)  [%-*s](fg:red)  [%-*s](fg:cyan)  [%-*s](fg:white)  [%-*s](fg:white)",
-					bankWidth, bankInfo,
-					rateWidth, rate.CurrentRate,
-					rateWidth, rate.PreviousRate,
-					changeWidth, changeValue,
-					dateWidth, changeDate,
-					historyWidth, historyRange,
-					rateWidth, rate.NextForecast)
-				rows = append(rows, row)
+		anns = make([]annotations, len(snapshots))
+		for i, snapshot := range snapshots {
+			anns[i] = loadAnnotations(db, snapshot)
+			markWatched(&anns[i], snapshot, wl)
+			if hits := alerts.applyRules(snapshot, &anns[i]); len(hits) > 0 {
+				hit := hits[len(hits)-1]
+				state.message = fmt.Sprintf("规则命中 %d 个新事件，最近: %s", len(hits), hit.Rule.Name)
+				statusBar.Text = state.statusText()
 			}
 		}
-
-		if len(rows) == 0 {
-			rows = []string{"[暂无数据](fg:red)"}
-		}
-		dataList.Rows = rows
-
-		// 渲染UI
-		if showingHelp {
-			termui.Render(header, dataList, statusBar, helpMenu)
-		} else {
-			termui.Render(header, dataList, statusBar)
-		}
+		renderData()
 	}
 
 	// 初始更新
-	updateUI()
+	updateUI(false)
 
 	// 设置定时器更新倒计时
 	countdownTicker := time.NewTicker(time.Second)
 	defer countdownTicker.Stop()
 
 	// 设置数据刷新定时器
-	refreshTicker := time.NewTicker(time.Duration(config.RefreshInterval) * time.Second)
+	refreshTicker := time.NewTicker(time.Duration(cfg.RefreshInterval) * time.Second)
 	defer refreshTicker.Stop()
 
 	// 更新下次刷新时间
-	state.nextRefreshTime = time.Now().Add(time.Duration(config.RefreshInterval) * time.Second)
+	state.nextRefreshTime = time.Now().Add(time.Duration(cfg.RefreshInterval) * time.Second)
+
+	// 定时检查配置文件是否修改
+	configTicker := time.NewTicker(config.DefaultPollInterval)
+	defer configTicker.Stop()
 
 	uiEvents := termui.PollEvents()
 	for {
@@ -423,83 +490,350 @@ func displayData(fetcher *htmlfetcher.DefaultFetcher, state *AppState, config *C
 				continue
 			}
 
+			// 输入跳转日期
+			if state.inputMode {
+				state.mu.Lock()
+				switch e.ID {
+				case "<Enter>":
+					state.inputMode = false
+					day, err := parseDateInput(state.inputBuffer, state.sourceLocation)
+					if err != nil {
+						state.message = err.Error()
+					} else {
+						state.setDay(day)
+						state.message = ""
+					}
+				case "<Escape>", "<C-c>":
+					state.inputMode = false
+				case "<Backspace>", "<C-<Backspace>>":
+					if n := len(state.inputBuffer); n > 0 {
+						state.inputBuffer = state.inputBuffer[:n-1]
+					}
+				default:
+					if len(e.ID) == 1 {
+						state.inputBuffer += e.ID
+					}
+				}
+				inputMode := state.inputMode
+				statusBar.Text = state.statusText()
+				state.mu.Unlock()
+				if inputMode {
+					termui.Render(statusBar)
+				} else {
+					updateUI(false)
+				}
+				continue
+			}
+
 			switch e.ID {
 			case "q", "<C-c>":
 				return
+			case "<Left>", "[", "<Right>", "]", "t":
+				state.mu.Lock()
+				step := 1
+				if state.displayMode == ModeWeek {
+					step = 7
+				}
+				switch e.ID {
+				case "<Left>", "[":
+					state.setDay(state.currentDay().AddDate(0, 0, -step))
+				case "<Right>", "]":
+					state.setDay(state.currentDay().AddDate(0, 0, step))
+				case "t":
+					state.setDay(state.today())
+				}
+				state.message = ""
+				state.mu.Unlock()
+				updateUI(false)
+			case "<Up>", "k":
+				if historyList != nil {
+					historyList.ScrollUp()
+					termui.Render(historyList)
+					continue
+				}
+				dataList.ScrollUp()
+				termui.Render(dataList)
+			case "<Down>", "j":
+				if historyList != nil {
+					historyList.ScrollDown()
+					termui.Render(historyList)
+					continue
+				}
+				dataList.ScrollDown()
+				termui.Render(dataList)
+			case "<Enter>", "<Space>", "i":
+				state.mu.Lock()
+				ref, ok := refs[dataList.SelectedRow]
+				switch {
+				case state.displayMode == ModeWeek && e.ID != "i":
+					// 一周视图中折叠或展开日期
+					if ok {
+						state.toggleDay(ref.day)
+						renderData()
+					}
+				case !ok || ref.event == nil:
+					state.message = "光标所在行不是财经日历事件，没有历史数据"
+				case db == nil:
+					state.message = "回放模式不读取数据库，没有历史数据"
+				default:
+					popup, err := historyPopup(db, ref.event, cfg.Location())
+					if err != nil {
+						logger.Error("查询历史数据失败", zap.Error(err))
+						state.message = err.Error()
+					} else {
+						historyList = popup
+					}
+				}
+				statusBar.Text = state.statusText()
+				render()
+				state.mu.Unlock()
+			case "w":
+				state.mu.Lock()
+				if ref, ok := refs[dataList.SelectedRow]; ok && (ref.event != nil || ref.important != nil) {
+					region, name := ref.subject()
+					watched, err := wl.Toggle(region, name)
+					switch {
+					case err != nil:
+						logger.Error("保存关注列表失败", zap.Error(err))
+						state.message = err.Error()
+					case watched:
+						state.message = "已关注: " + region + " " + name
+					default:
+						state.message = "已取消关注: " + region + " " + name
+					}
+					for i := range snapshots {
+						markWatched(&anns[i], snapshots[i], wl)
+					}
+					statusBar.Text = state.statusText()
+					renderData()
+				}
+				state.mu.Unlock()
+			case "e":
+				// 持有锁时只生成要导出的表，在锁外写文件
+				state.mu.Lock()
+				ready := refs != nil && len(anns) == len(snapshots)
+				var path string
+				var sheets []export.Sheet
+				opts := export.Options{Format: cfg.Export.Format, BOM: cfg.Export.BOM}
+				if ready {
+					path = filepath.Join(cfg.Export.Dir, exportFileName(state.currentDay(), state.displayMode, opts.Format, time.Now()))
+					sheets = exportSheets(snapshots, anns, state.displayMode, state.collapsedDays, cfg.Location())
+				}
+				state.mu.Unlock()
+
+				message := "没有可导出的数据"
+				if ready {
+					if err := writeExportFile(path, sheets, opts); err != nil {
+						logger.Error("导出失败", zap.Error(err))
+						message = err.Error()
+					} else {
+						logger.Info("已导出当前视图", zap.String("path", path))
+						message = "已导出到 " + path
+					}
+				}
+
+				state.mu.Lock()
+				state.message = message
+				statusBar.Text = state.statusText()
+				state.mu.Unlock()
+				termui.Render(statusBar)
+			case "g":
+				state.mu.Lock()
+				state.inputMode = true
+				state.inputBuffer = ""
+				statusBar.Text = state.statusText()
+				state.mu.Unlock()
+				termui.Render(statusBar)
 			case "r":
-				updateUI()
-				state.nextRefreshTime = time.Now().Add(time.Duration(config.RefreshInterval) * time.Second)
+				updateUI(true)
+				state.nextRefreshTime = time.Now().Add(time.Duration(cfg.RefreshInterval) * time.Second)
 			case "p":
 				state.togglePause()
 				termui.Render(statusBar)
 			case "m":
 				state.nextMode()
-				updateUI()
+				updateUI(false)
 			case "h":
 				showingHelp = !showingHelp
-				if showingHelp {
-					termui.Render(header, dataList, statusBar, helpMenu)
-				} else {
-					termui.Render(header, dataList, statusBar)
-				}
+				render()
 			case "<Escape>":
 				if showingHelp {
 					showingHelp = false
-					termui.Render(header, dataList, statusBar)
+				} else {
+					historyList = nil
 				}
+				render()
 			case "<Resize>":
+				historyList = nil
 				updateLayout()
-				updateUI()
+				updateUI(false)
 			}
 		case <-countdownTicker.C:
 			// 更新倒计时
 			state.mu.Lock()
-			statusBar.Text = fmt.Sprintf("模式: %s | 状态: %s | 下次刷新: %s",
-				map[DisplayMode]string{
-					ModeHighImportance: "仅显示高重要性",
-					ModeAll:            "显示所有数据",
-					ModeWithRates:      "显示高重要性+利率信息",
-					ModeWithImportant:  "显示高重要性+重要事件",
-				}[state.displayMode],
-				map[bool]string{true: "已暂停", false: "运行中"}[state.isPaused],
-				formatCountdown(state.nextRefreshTime))
+			statusBar.Text = state.statusText()
+			header.Text = state.headerText()
 			state.mu.Unlock()
-			termui.Render(statusBar)
+			termui.Render(header, statusBar)
 		case <-refreshTicker.C:
-			if !state.isPaused {
-				updateUI()
-				state.nextRefreshTime = time.Now().Add(time.Duration(config.RefreshInterval) * time.Second)
+			if !state.isPaused && !state.inputMode && state.autoRefresh() {
+				updateUI(false)
+				state.nextRefreshTime = time.Now().Add(time.Duration(cfg.RefreshInterval) * time.Second)
+				// 回放越过最后一个快照后显示的已是最终数据，停止自动刷新
+				if state.replayDone != nil && state.replayDone() {
+					state.mu.Lock()
+					state.isPaused = true
+					state.message = "回放结束"
+					statusBar.Text = state.statusText()
+					state.mu.Unlock()
+					termui.Render(statusBar)
+				}
 			}
+		case <-configTicker.C:
+			next, changed, err := reloader.Check()
+			if !changed {
+				continue
+			}
+			if err != nil {
+				// 配置无效时继续使用原配置
+				logger.Error("重新加载配置失败", zap.Error(err))
+				state.mu.Lock()
+				state.message = configErrorText(err)
+				statusBar.Text = state.statusText()
+				state.mu.Unlock()
+				termui.Render(statusBar)
+				continue
+			}
+
+			state.mu.Lock()
+			state.message = state.applyConfig(cfg, next)
+			state.mu.Unlock()
+			logger.Info("配置已重新加载", zap.Strings("config_files", cfg.Files))
+
+			interval := time.Duration(cfg.RefreshInterval) * time.Second
+			refreshTicker.Reset(interval)
+			state.nextRefreshTime = time.Now().Add(interval)
+			updateLayout()
+			updateUI(false)
 		}
 	}
 }
 
 func main() {
-	// 初始化日志
-	logPath := filepath.Join("logs", "app.log")
-	if _, err := logger.NewLogger(logPath); err != nil {
+	name, args := "tui", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		printUsage(os.Stdout)
+		return
+	}
+	cmd := findCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "未知的命令: %s\n\n", name)
+		printUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// 每个子命令都接受配置参数
+	fs := flag.NewFlagSet("fmcl "+name, flag.ExitOnError)
+	flags := config.BindFlags(fs)
+	run := cmd.setup(fs)
+	fs.Parse(args)
+
+	// 加载配置：默认值 → 系统文件 → 用户文件 → 当前目录文件 → 环境变量 → 命令行参数
+	options := config.Options{Flags: flags}
+	cfg, err := config.Load(options)
+	if err != nil {
+		log.Fatalf("加载配置失败: %v", err)
+	}
+
+	// 初始化日志。日志只写入文件：终端界面独占终端，其他命令的标准输出用于输出结果
+	if _, err := logger.NewFileLogger(cfg.LogPath); err != nil {
 		log.Fatalf("初始化日志失败: %v", err)
 	}
 	defer logger.Log.Sync()
 
-	logger.Info("程序启动")
+	if err := run(&cliContext{cfg: cfg, options: options, args: fs.Args(), out: os.Stdout}); err != nil {
+		logger.Error("命令执行失败", zap.String("command", name), zap.Error(err))
+		fmt.Fprintln(os.Stderr, err)
+		logger.Log.Sync()
+		os.Exit(1)
+	}
+}
 
-	// 加载配置
-	config, err := loadConfig()
-	if err != nil {
-		logger.Error("加载配置失败", zap.Error(err))
-		return
+// 注册 tui 命令的参数
+func tuiCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	archiveDir := fs.String("archive", "", "将下载的原始页面压缩归档到指定目录")
+	replayDir := fs.String("replay", "", "从归档目录回放页面，不访问网络")
+	replaySpeed := fs.Float64("replay-speed", 1, "回放倍速，1 为真实速度")
+	return func(c *cliContext) error {
+		return runTUI(c, *archiveDir, *replayDir, *replaySpeed)
 	}
+}
+
+// 运行终端界面
+func runTUI(c *cliContext, archiveDir, replayDir string, replaySpeed float64) error {
+	cfg := c.cfg
+	reloader := config.NewReloader(c.options)
+	logger.Info("程序启动", zap.Strings("config_files", cfg.Files))
+
+	parser.RegisterColumnAliases(cfg.ColumnAliases)
 
 	// 初始化应用状态
 	state := &AppState{
-		displayMode: DisplayMode(config.DefaultDisplayMode),
+		displayMode: DisplayMode(cfg.DefaultDisplayMode),
 		startTime:   time.Now(),
 	}
 
 	// 初始化数据获取器
-	fetcher := &htmlfetcher.DefaultFetcher{}
+	var fetcher htmlfetcher.Fetcher = &htmlfetcher.DefaultFetcher{}
+	var db *storage.DB
+	if replayDir != "" {
+		// 回放模式不访问网络，也不写入数据库
+		replayer, err := htmlfetcher.NewReplayer(replayDir, replaySpeed)
+		if err != nil {
+			return fmt.Errorf("初始化回放失败: %v", err)
+		}
+		fetcher = replayer
+		state.replaying = true
+		state.clock = replayer.Now
+		state.replayDone = replayer.Done
+		logger.Info("回放模式", zap.String("dir", replayDir), zap.Float64("speed", replaySpeed))
+	} else {
+		if archiveDir != "" {
+			fetcher = htmlfetcher.NewArchivingFetcher(fetcher, archiveDir)
+		}
+
+		// 初始化数据库
+		var err error
+		db, err = storage.NewDB(cfg.DatabasePath)
+		if err != nil {
+			return fmt.Errorf("初始化数据库失败: %v", err)
+		}
+		defer db.Close()
+	}
+
+	// 初始化数据源
+	src, err := source.NewFromConfig(cfg.Sources, fetcher)
+	if err != nil {
+		return fmt.Errorf("初始化数据源失败: %v", err)
+	}
+	state.sourceLocation = src.Location()
+
+	// 初始化公布提醒
+	alerts, err := newAlerter(cfg)
+	if err != nil {
+		return fmt.Errorf("初始化提醒失败: %v", err)
+	}
+
+	// 加载关注列表
+	wl, err := watchlist.Load(cfg.Watchlist)
+	if err != nil {
+		return fmt.Errorf("加载关注列表失败: %v", err)
+	}
 
 	// 显示数据
-	displayData(fetcher, state, config)
+	displayData(src, db, alerts, wl, state, cfg, reloader)
+	return nil
 }
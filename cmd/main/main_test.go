@@ -0,0 +1,469 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher/htmlfetchertest"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+	"github.com/yourusername/fmcl/pkg/watchlist"
+)
+
+// 测试用配置
+func testConfig() *config.AppConfig {
+	return config.Default()
+}
+
+// 从本地替身服务器获取并解析指定日期的页面
+func fetchFixture(t *testing.T, day time.Time) source.Snapshot {
+	t.Helper()
+	server := htmlfetchertest.NewServer(filepath.Join("..", "..", "testdata", "fx678"))
+	t.Cleanup(server.Close)
+
+	src := source.NewFX678(&htmlfetcher.DefaultFetcher{})
+	src.BaseURL = server.URL
+	snapshots, err := src.Fetch(day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return snapshots[0]
+}
+
+func TestRenderRowsHighImportance(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	rows, _ := renderRows(snapshot, annotations{}, ModeHighImportance, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+
+	for _, want := range []string{"1月ADP就业人数(万)", "1月ISM非制造业PMI"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("高重要性模式缺少 %s", want)
+		}
+	}
+	for _, unwanted := range []string{"12月贸易帐(亿美元)", "央行利率信息", "重要事件"} {
+		if strings.Contains(text, unwanted) {
+			t.Errorf("高重要性模式不应包含 %s", unwanted)
+		}
+	}
+}
+
+func TestRenderRowsAll(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	rows, _ := renderRows(snapshot, annotations{}, ModeAll, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+
+	for _, want := range []string{"12月贸易帐(亿美元)", "=== 重要事件 ===", "欧盟财长会议", "美联储 - 联邦基金利率"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("全部模式缺少 %s", want)
+		}
+	}
+}
+
+func TestRenderRowsRevisedMarker(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	ann := annotations{revised: map[string]map[string]bool{
+		eventKey("21:15", "美国", "1月ADP就业人数(万)"): {storage.FieldPrevious: true},
+	}}
+	rows, _ := renderRows(snapshot, ann, ModeHighImportance, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+
+	if !strings.Contains(text, "[12.2*") {
+		t.Error("被修正的前值应带*标记")
+	}
+}
+
+func TestRenderRowsLayoutChanged(t *testing.T) {
+	snapshot := source.Snapshot{
+		Warnings: []parser.Warning{{Kind: parser.WarningMissingTable, Table: parser.TableCalendar}},
+	}
+	rows, _ := renderRows(snapshot, annotations{}, ModeHighImportance, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+
+	if !strings.Contains(text, "数据源页面结构已变化") {
+		t.Error("页面结构变化时应提示，而不是显示空表")
+	}
+}
+
+func TestRenderRowsSurprise(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	ann := loadAnnotations(nil, snapshot)
+	rows, _ := renderRows(snapshot, ann, ModeHighImportance, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+
+	// ADP 公布 18.3 万，预测 15 万，利多美元
+	if !strings.Contains(text, "[+3.3万") || !strings.Contains(text, "(fg:red)  [1月ADP就业人数(万)]") {
+		t.Errorf("应显示红色的利多意外值:\n%s", text)
+	}
+}
+
+func TestRenderRowsDisplayTimezone(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	cfg := testConfig()
+	cfg.Timezone = "America/New_York"
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := renderRows(snapshot, annotations{}, ModeHighImportance, cfg, 120)
+	text := strings.Join(rows, "\n")
+
+	// 北京时间 21:15 为纽约时间 08:15；北京时间 09:45 为纽约前一天 20:45
+	if !strings.Contains(text, "[08:15 ") || !strings.Contains(text, "[20:45-1") {
+		t.Errorf("应按显示时区显示时间:\n%s", text)
+	}
+}
+
+func TestParseDateInput(t *testing.T) {
+	loc := time.FixedZone("CST", 8*60*60)
+	for _, input := range []string{"20250205", "2025-02-05", " 2025/02/05 "} {
+		day, err := parseDateInput(input, loc)
+		if err != nil {
+			t.Errorf("parseDateInput(%q): %v", input, err)
+			continue
+		}
+		if !day.Equal(time.Date(2025, 2, 5, 0, 0, 0, 0, loc)) {
+			t.Errorf("parseDateInput(%q) = %v", input, day)
+		}
+	}
+	if _, err := parseDateInput("明天", loc); err == nil {
+		t.Error("无法识别的日期应返回错误")
+	}
+}
+
+func TestLoadDayServesPastDaysFromStore(t *testing.T) {
+	server := htmlfetchertest.NewServer(filepath.Join("..", "..", "testdata", "fx678"))
+	t.Cleanup(server.Close)
+	src := source.NewFX678(&htmlfetcher.DefaultFetcher{})
+	src.BaseURL = server.URL
+
+	db, err := storage.NewDB(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	day := time.Date(2025, 2, 5, 0, 0, 0, 0, src.Location())
+	today := day.AddDate(0, 0, 3)
+
+	// 第一次从数据源获取并保存
+	fetched, err := loadDay(src, db, day, today, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched.Source != "fx678" || len(server.Requests()) != 1 {
+		t.Fatalf("本地没有数据时应从数据源获取: %s %v", fetched.Source, server.Requests())
+	}
+
+	// 第二次直接使用本地存储
+	stored, err := loadDay(src, db, day, today, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Source != "本地存储" || len(server.Requests()) != 1 {
+		t.Fatalf("过去的日期应使用本地存储: %s %v", stored.Source, server.Requests())
+	}
+	if len(stored.Events) != len(fetched.Events) || len(stored.Rates) != len(fetched.Rates) {
+		t.Errorf("本地存储的数据不完整: events %d/%d rates %d/%d",
+			len(stored.Events), len(fetched.Events), len(stored.Rates), len(fetched.Rates))
+	}
+}
+
+func TestLoadDayRefetchesStalePastDays(t *testing.T) {
+	server := htmlfetchertest.NewServer(filepath.Join("..", "..", "testdata", "fx678"))
+	t.Cleanup(server.Close)
+	src := source.NewFX678(&htmlfetcher.DefaultFetcher{})
+	src.BaseURL = server.URL
+
+	db, err := storage.NewDB(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// 数据是在当天结束前获取的，重新获取一次；之后的数据已是最终结果
+	day := time.Date(2025, 2, 7, 0, 0, 0, 0, src.Location())
+	today := day.AddDate(0, 0, 3)
+	if _, err := loadDay(src, db, day, today, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, table := range []string{"calendar_events", "important_events", "central_bank_rates"} {
+		if _, err := db.Conn.Exec("UPDATE "+table+" SET updated_at = ? WHERE date = ?", day.Add(20*time.Hour), "2025-02-07"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, want := range []string{"fx678", "本地存储"} {
+		snapshot, err := loadDay(src, db, day, today, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.Source != want || len(server.Requests()) != 2 {
+			t.Fatalf("第%d次加载: 来源 %s, 请求 %v", i+1, snapshot.Source, server.Requests())
+		}
+	}
+
+	// 强制刷新时也重新获取
+	day = time.Date(2025, 2, 5, 0, 0, 0, 0, src.Location())
+	for i := 3; i <= 4; i++ {
+		snapshot, err := loadDay(src, db, day, today, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if snapshot.Source != "fx678" || len(server.Requests()) != i {
+			t.Fatalf("强制刷新应从数据源获取: %s %v", snapshot.Source, server.Requests())
+		}
+	}
+}
+
+func TestDisplayModeCount(t *testing.T) {
+	if len(modeNames) != config.DisplayModeCount {
+		t.Errorf("显示模式 %d 个，配置校验允许 %d 个", len(modeNames), config.DisplayModeCount)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	cfg := testConfig()
+	state := &AppState{displayMode: ModeAll}
+
+	next := testConfig()
+	next.RefreshInterval = 60
+	if msg := state.applyConfig(cfg, next); msg != "配置已重新加载" || state.displayMode != ModeAll {
+		t.Errorf("默认模式未变时不应切换模式: %q %v", msg, state.displayMode)
+	}
+
+	next = testConfig()
+	next.DefaultDisplayMode = int(ModeWeek)
+	next.Watchlist = "other.yaml"
+	msg := state.applyConfig(cfg, next)
+	if state.displayMode != ModeWeek || cfg.RefreshInterval != 15 {
+		t.Errorf("模式 = %v, 刷新间隔 = %d", state.displayMode, cfg.RefreshInterval)
+	}
+	if !strings.Contains(msg, "watchlist 需重启后生效") {
+		t.Errorf("提示 = %q", msg)
+	}
+}
+
+func TestConfigErrorText(t *testing.T) {
+	cfg := testConfig()
+	cfg.RefreshInterval = 0
+	cfg.UI.ValueWidth = 0
+	got := configErrorText(cfg.Validate())
+	want := "配置未生效: refresh_interval 必须大于0（秒），当前为 0; ui.value_width 必须大于0，当前为 0"
+	if got != want {
+		t.Errorf("configErrorText = %q, 期望 %q", got, want)
+	}
+}
+
+func TestStartOfWeek(t *testing.T) {
+	for _, day := range []int{3, 5, 9} {
+		got := startOfWeek(time.Date(2025, 2, day, 0, 0, 0, 0, time.UTC))
+		if got.Format("2006-01-02") != "2025-02-03" {
+			t.Errorf("2025-02-%02d 所在周的周一 = %s, 期望 2025-02-03", day, got.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestRenderWeekRows(t *testing.T) {
+	server := htmlfetchertest.NewServer(filepath.Join("..", "..", "testdata", "fx678"))
+	t.Cleanup(server.Close)
+	src := source.NewFX678(&htmlfetcher.DefaultFetcher{})
+	src.BaseURL = server.URL
+
+	monday := time.Date(2025, 2, 3, 0, 0, 0, 0, src.Location())
+	snapshots, err := loadWeek(src, nil, monday)
+	if err != nil {
+		t.Fatal(err)
+	}
+	anns := make([]annotations, len(snapshots))
+
+	rows, refs := renderWeekRows(snapshots, anns, nil, monday, testConfig(), 120)
+	for i, row := range rows {
+		if strings.Contains(row, "▼ 2025-02-05") && !refs[i].day.Equal(monday.AddDate(0, 0, 2)) {
+			t.Errorf("日期标题行应对应 2025-02-05: %+v", refs[i])
+		}
+	}
+	text := strings.Join(rows, "\n")
+	for _, want := range []string{"▼ 2025-02-03 周一", "▼ 2025-02-05 周三", "1月ADP就业人数(万)", "▼ 2025-02-09 周日", "无高重要性事件"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("一周视图缺少 %s", want)
+		}
+	}
+	if strings.Contains(text, "12月贸易帐(亿美元)") {
+		t.Error("一周视图只应显示高重要性事件")
+	}
+
+	// 折叠周三后不再显示当天的事件
+	collapsed := map[string]bool{"2025-02-05": true}
+	rows, _ = renderWeekRows(snapshots, anns, collapsed, monday, testConfig(), 120)
+	text = strings.Join(rows, "\n")
+	if !strings.Contains(text, "▶ 2025-02-05 周三") || strings.Contains(text, "1月ADP就业人数(万)") {
+		t.Error("折叠的日期应只显示标题")
+	}
+}
+
+func TestNewAlerter(t *testing.T) {
+	cfg := testConfig()
+	if a, err := newAlerter(cfg); a != nil || err != nil {
+		t.Errorf("未配置提醒方式和规则时应不启用提醒: %v %v", a, err)
+	}
+
+	cfg.NotificationMethods = []string{"console"}
+	cfg.Notification.LeadMinutes = 10
+	a, err := newAlerter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.watcher.Lead != 10*time.Minute || len(a.dispatcher.Backends) != 1 {
+		t.Errorf("提醒设置 = %v %+v", a.watcher.Lead, a.dispatcher.Backends)
+	}
+
+	cfg.NotificationMethods = []string{"webhook"}
+	if _, err := newAlerter(cfg); err == nil {
+		t.Error("webhook 缺少地址时应返回错误")
+	}
+
+	cfg.NotificationMethods = nil
+	cfg.Rules = []config.RuleConfig{{Name: "提醒", Actions: []string{"notify"}}}
+	if _, err := newAlerter(cfg); err == nil {
+		t.Error("规则使用 notify 但没有提醒方式时应返回错误")
+	}
+}
+
+func TestApplyRulesHighlightsOnce(t *testing.T) {
+	cfg := testConfig()
+	cfg.Rules = []config.RuleConfig{{
+		Name:      "就业",
+		Regions:   []string{"美国"},
+		Indicator: "ADP|欧盟财长",
+		Actions:   []string{"highlight", "sound"},
+	}}
+	a, err := newAlerter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bell strings.Builder
+	a.bell = &bell
+
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	ann := annotations{}
+	if hits := a.applyRules(snapshot, &ann); len(hits) != 1 {
+		t.Fatalf("首次命中数 = %d, 期望 1", len(hits))
+	}
+	rows, _ := renderRows(snapshot, ann, ModeHighImportance, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+	if !strings.Contains(text, "[1月ADP就业人数(万)](fg:black,bg:yellow)") {
+		t.Error("命中规则的事件应高亮")
+	}
+
+	// 再次刷新仍然高亮，但不再响铃
+	ann = annotations{}
+	if hits := a.applyRules(snapshot, &ann); len(hits) != 0 || !ann.highlighted[eventKey("21:15", "美国", "1月ADP就业人数(万)")] {
+		t.Errorf("再次刷新: 新命中 %d, 高亮 %v", len(hits), ann.highlighted)
+	}
+	if bell.String() != "\a" {
+		t.Errorf("响铃 %q, 期望只响一次", bell.String())
+	}
+}
+
+func TestApplyRulesKeepsShownPastDays(t *testing.T) {
+	cfg := testConfig()
+	cfg.Rules = []config.RuleConfig{{Name: "就业", Indicator: "ADP", Actions: []string{"sound"}}}
+	a, err := newAlerter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bell strings.Builder
+	a.bell = &bell
+
+	// 2月5日为昨天，以及查看上一周的某天时，每次刷新前清理记录都不应让命中重新变为首次
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	loc := snapshot.Date.Location()
+	for _, today := range []time.Time{time.Date(2025, 2, 6, 0, 0, 0, 0, loc), time.Date(2025, 2, 12, 0, 0, 0, 0, loc)} {
+		for i := 0; i < 2; i++ {
+			a.check([]source.Snapshot{snapshot}, today, today.Add(12*time.Hour))
+			a.applyRules(snapshot, &annotations{})
+		}
+	}
+	if bell.String() != "\a" {
+		t.Errorf("响铃 %q, 期望只响一次", bell.String())
+	}
+}
+
+func TestApplyRulesFollowsReloadedTimezone(t *testing.T) {
+	cfg := testConfig()
+	cfg.Timezone = "Asia/Shanghai"
+	cfg.Rules = []config.RuleConfig{{Name: "晚间", Indicator: "ADP", Window: "21:00-21:30", Actions: []string{"highlight"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	a, err := newAlerter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	key := eventKey("21:15", "美国", "1月ADP就业人数(万)")
+	ann := annotations{}
+	a.applyRules(snapshot, &ann)
+	if !ann.highlighted[key] {
+		t.Fatal("北京时间 21:15 应在时间窗口内")
+	}
+
+	// 重新加载配置后按新时区匹配时间窗口，此时为 UTC 13:15
+	next := testConfig()
+	next.Timezone = "UTC"
+	next.Rules = cfg.Rules
+	if err := next.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Apply(next)
+	ann = annotations{}
+	a.applyRules(snapshot, &ann)
+	if ann.highlighted[key] {
+		t.Error("时区改变后应按新时区匹配时间窗口")
+	}
+}
+
+func TestRenderRowsWatchlist(t *testing.T) {
+	snapshot := fetchFixture(t, time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC))
+	wl, err := watchlist.Load(filepath.Join(t.TempDir(), "watchlist.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 通过行对应的事件加入关注
+	ann := annotations{}
+	rows, refs := renderRows(snapshot, ann, ModeAll, testConfig(), 120)
+	for i, row := range rows {
+		if strings.Contains(row, "12月贸易帐(亿美元)") || strings.Contains(row, "欧盟财长会议") {
+			region, name := refs[i].subject()
+			if _, err := wl.Toggle(region, name); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if wl.Len() != 2 {
+		t.Fatalf("关注数 = %d, 期望 2", wl.Len())
+	}
+	markWatched(&ann, snapshot, wl)
+
+	rows, _ = renderRows(snapshot, ann, ModeWatchlist, testConfig(), 120)
+	text := strings.Join(rows, "\n")
+	for _, want := range []string{"[★](fg:yellow) [12月贸易帐(亿美元)]", "[★](fg:yellow) [欧盟财长会议 | 第二日]"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("关注模式缺少 %s", want)
+		}
+	}
+	if strings.Contains(text, "1月ADP就业人数(万)") || strings.Contains(text, "央行利率信息") {
+		t.Error("关注模式只应显示关注的事件")
+	}
+
+	// 其它模式下关注的事件带★标记
+	rows, _ = renderRows(snapshot, ann, ModeAll, testConfig(), 120)
+	if text := strings.Join(rows, "\n"); !strings.Contains(text, "[★](fg:yellow) [12月贸易帐(亿美元)]") || strings.Contains(text, "[★](fg:yellow) [1月ADP") {
+		t.Error("全部模式中只有关注的事件应带★标记")
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// 星期名称
+var weekdayNames = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// 解析用户输入的日期，返回 loc 时区下当天零点
+func parseDateInput(input string, loc *time.Location) (time.Time, error) {
+	input = strings.TrimSpace(input)
+	for _, layout := range source.DateLayouts {
+		if day, err := time.ParseInLocation(layout, input, loc); err == nil {
+			return day, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法识别的日期 %q，请使用 YYYYMMDD 或 YYYY-MM-DD", input)
+}
+
+// 返回 t 在 loc 时区下当天零点
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// 从本地存储构造某一天的快照，没有记录时返回 false
+func storedSnapshot(db *storage.DB, day time.Time) (source.Snapshot, bool, error) {
+	if db == nil {
+		return source.Snapshot{}, false, nil
+	}
+
+	date := day.Format("2006-01-02")
+	stored, err := db.CalendarEvents(date)
+	if err != nil {
+		return source.Snapshot{}, false, err
+	}
+	importantEvents, err := db.ImportantEvents(date)
+	if err != nil {
+		return source.Snapshot{}, false, err
+	}
+	rates, err := db.CentralBankRates(date)
+	if err != nil {
+		return source.Snapshot{}, false, err
+	}
+	if len(stored) == 0 && len(importantEvents) == 0 && len(rates) == 0 {
+		return source.Snapshot{}, false, nil
+	}
+
+	snapshot := source.Snapshot{
+		Source:          "本地存储",
+		Date:            day,
+		ImportantEvents: importantEvents,
+		Rates:           rates,
+	}
+	for _, e := range stored {
+		snapshot.Events = append(snapshot.Events, e.CalendarEvent)
+	}
+	return snapshot, true, nil
+}
+
+// 获取某一天的数据：过去的日期优先使用本地存储，其余从数据源获取并保存，
+// 数据源失败时退回本地存储。本地存储的数据是在当天结束前获取的，或 force 为 true 时，
+// 过去的日期也重新获取
+func loadDay(src source.Source, db *storage.DB, day, today time.Time, force bool) (source.Snapshot, error) {
+	if day.Before(today) && !force && fetchedAfterDay(db, day) {
+		if snapshot, ok, err := storedSnapshot(db, day); err == nil && ok {
+			return snapshot, nil
+		}
+	}
+
+	snapshots, err := src.Fetch(day, day)
+	if err == nil && len(snapshots) == 0 {
+		err = fmt.Errorf("数据源没有返回 %s 的数据", day.Format("2006-01-02"))
+	}
+	if err != nil {
+		if snapshot, ok, _ := storedSnapshot(db, day); ok {
+			return snapshot, nil
+		}
+		return source.Snapshot{}, err
+	}
+	saveSnapshot(db, snapshots[0])
+	return snapshots[0], nil
+}
+
+// 本地存储中某一天的数据是否在当天结束后获取过，此后页面不会再变化
+func fetchedAfterDay(db *storage.DB, day time.Time) bool {
+	if db == nil {
+		return false
+	}
+	updatedAt, err := db.UpdatedAt(day.Format("2006-01-02"))
+	return err == nil && !updatedAt.Before(day.AddDate(0, 0, 1))
+}
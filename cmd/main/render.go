@@ -0,0 +1,369 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+	"github.com/yourusername/fmcl/pkg/surprise"
+	"github.com/yourusername/fmcl/pkg/watchlist"
+)
+
+// 渲染时附加在财经日历事件上的信息，均以 eventKey 为索引
+type annotations struct {
+	revised     map[string]map[string]bool    // 被修正过的字段
+	surprises   map[string]*surprise.Surprise // 意外值
+	highlighted map[string]bool               // 被规则高亮的事件，包括重要事件
+	watched     map[string]bool               // 关注列表中的事件，包括重要事件
+}
+
+// 列表中一行对应的日期和事件，以行号为索引，标题和分隔行没有记录
+type rowRef struct {
+	day       time.Time
+	event     *parser.CalendarEvent
+	important *parser.ImportantEvent
+}
+
+// 行对应事件的地区和名称
+func (r rowRef) subject() (region, name string) {
+	if r.event != nil {
+		return r.event.Region, r.event.Indicator
+	}
+	if r.important != nil {
+		return r.important.Region, r.important.Event
+	}
+	return "", ""
+}
+
+// 事件的索引键
+func eventKey(eventTime, region, indicator string) string {
+	return eventTime + "|" + region + "|" + indicator
+}
+
+// 加载事件的修正标记与意外值
+func loadAnnotations(db *storage.DB, snapshot source.Snapshot) annotations {
+	date := snapshot.Date.Format("2006-01-02")
+	ann := annotations{
+		revised:   loadRevisedFields(db, date),
+		surprises: make(map[string]*surprise.Surprise),
+	}
+
+	for _, e := range snapshot.Events {
+		key := eventKey(e.Time, e.Region, e.Indicator)
+		if db == nil {
+			if s, ok := surprise.Compute(e); ok {
+				ann.surprises[key] = &s
+			}
+			continue
+		}
+		s, err := db.ScoreSurprise(date, e)
+		if err != nil {
+			logger.Error("计算意外值失败", zap.String("indicator", e.Indicator), zap.Error(err))
+			continue
+		}
+		if s != nil {
+			ann.surprises[key] = s
+		}
+	}
+	return ann
+}
+
+// 标记关注列表中的财经日历事件和重要事件
+func markWatched(ann *annotations, snapshot source.Snapshot, wl *watchlist.Watchlist) {
+	ann.watched = make(map[string]bool)
+	if wl == nil {
+		return
+	}
+	for _, e := range snapshot.Events {
+		if wl.Contains(e.Region, e.Indicator) {
+			ann.watched[eventKey(e.Time, e.Region, e.Indicator)] = true
+		}
+	}
+	for _, e := range snapshot.ImportantEvents {
+		if wl.Contains(e.Region, e.Event) {
+			ann.watched[eventKey(e.Time, e.Region, e.Event)] = true
+		}
+	}
+}
+
+// 加载指定日期被修正过的字段，首次公布不算修正
+func loadRevisedFields(db *storage.DB, date string) map[string]map[string]bool {
+	revised := make(map[string]map[string]bool)
+	if db == nil {
+		return revised
+	}
+	revisions, err := db.Revisions(date)
+	if err != nil {
+		logger.Error("读取修正记录失败", zap.Error(err))
+		return revised
+	}
+	for _, r := range revisions {
+		if r.Published() {
+			continue
+		}
+		key := eventKey(r.Time, r.Region, r.Indicator)
+		if revised[key] == nil {
+			revised[key] = make(map[string]bool)
+		}
+		revised[key][r.Field] = true
+	}
+	return revised
+}
+
+// 格式化意外值单元格：利多为红色，利空为绿色
+func formatSurpriseCell(s *surprise.Surprise, width int) string {
+	if s == nil {
+		return fmt.Sprintf("[%-*s](fg:white)", width, "")
+	}
+	color := "white"
+	switch s.Direction {
+	case surprise.DirectionBullish:
+		color = "red"
+	case surprise.DirectionBearish:
+		color = "green"
+	}
+	return fmt.Sprintf("[%-*s](fg:%s)", width, formatWidth(s.String(), width), color)
+}
+
+// 格式化数值单元格，被修正过的数值以黄色并带*标记显示
+func formatValueCell(value string, width int, color string, revised bool) string {
+	if revised {
+		return fmt.Sprintf("[%-*s](fg:yellow)", width, formatWidth(value+"*", width))
+	}
+	return fmt.Sprintf("[%-*s](fg:%s)", width, formatWidth(value, width), color)
+}
+
+// 按显示时区格式化事件时间；跨日时附加 +1/-1，时间未定时显示原文
+func displayTime(raw string, at time.Time, day time.Time, loc *time.Location) string {
+	if at.IsZero() {
+		return raw
+	}
+	local := at.In(loc)
+	text := local.Format("15:04")
+
+	// 与页面所属日期（数据源时区）比较日期差
+	y, m, d := local.Date()
+	dy, dm, dd := day.Date()
+	diff := int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Sub(time.Date(dy, dm, dd, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	if diff > 0 {
+		text += fmt.Sprintf("+%d", diff)
+	} else if diff < 0 {
+		text += fmt.Sprintf("%d", diff)
+	}
+	return text
+}
+
+// 意外值列宽度
+const surpriseWidth = 16
+
+// 显示模式是否显示该重要性的财经日历事件
+func showEvent(mode DisplayMode, importance string) bool {
+	return mode == ModeAll || importance == "高"
+}
+
+// 显示模式下是否显示该财经日历事件，关注模式只显示关注的事件
+func visibleEvent(mode DisplayMode, event parser.CalendarEvent, ann annotations) bool {
+	if mode == ModeWatchlist {
+		return ann.watched[eventKey(event.Time, event.Region, event.Indicator)]
+	}
+	return showEvent(mode, event.Importance)
+}
+
+// 显示模式是否显示重要事件表
+func showsImportantEvents(mode DisplayMode) bool {
+	return mode == ModeWithImportant || mode == ModeAll || mode == ModeWatchlist
+}
+
+// 显示模式下是否显示该重要事件
+func visibleImportantEvent(mode DisplayMode, event parser.ImportantEvent, ann annotations) bool {
+	if mode == ModeWatchlist {
+		return ann.watched[eventKey(event.Time, event.Region, event.Event)]
+	}
+	return event.Importance == "高" || mode == ModeAll
+}
+
+// 显示模式是否显示央行利率表
+func showsRates(mode DisplayMode) bool {
+	return mode == ModeWithRates || mode == ModeAll
+}
+
+// 财经日历事件的表头行
+func eventHeaderRow(cfg *config.AppConfig) string {
+	return fmt.Sprintf("[%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %s](fg:cyan)",
+		cfg.UI.TimeWidth, "时间",
+		cfg.UI.ImportanceWidth, "重要性",
+		cfg.UI.ValueWidth, "前值",
+		cfg.UI.ValueWidth, "预测",
+		cfg.UI.ValueWidth, "公布值",
+		surpriseWidth, "意外",
+		"指标名称")
+}
+
+// 格式化一行财经日历事件
+func formatEventRow(event parser.CalendarEvent, eventTime string, ann annotations, cfg *config.AppConfig) string {
+	importanceColor := "white"
+	if event.Importance == "高" {
+		importanceColor = "red"
+	} else if event.Importance == "中" {
+		importanceColor = "yellow"
+	}
+
+	key := eventKey(event.Time, event.Region, event.Indicator)
+	fields := ann.revised[key]
+	return fmt.Sprintf("[%-*s](fg:cyan)  [%-*s](fg:%s)  %s  %s  %s  %s  %s",
+		cfg.UI.TimeWidth, eventTime,
+		cfg.UI.ImportanceWidth, event.Importance,
+		importanceColor,
+		formatValueCell(event.Previous, cfg.UI.ValueWidth, "white", fields[storage.FieldPrevious]),
+		formatValueCell(event.Forecast, cfg.UI.ValueWidth, "white", false),
+		formatValueCell(event.Actual, cfg.UI.ValueWidth, "green", fields[storage.FieldActual]),
+		formatSurpriseCell(ann.surprises[key], surpriseWidth),
+		formatNameCell(event.Indicator, ann.highlighted[key], ann.watched[key]))
+}
+
+// 格式化指标或事件名称，被规则高亮的以黄底显示，关注的带★标记
+func formatNameCell(name string, highlighted, watched bool) string {
+	cell := fmt.Sprintf("[%s](fg:white)", name)
+	if highlighted {
+		cell = fmt.Sprintf("[%s](fg:black,bg:yellow)", name)
+	}
+	if watched {
+		cell = "[★](fg:yellow) " + cell
+	}
+	return cell
+}
+
+// 重要事件表是否有内容，关注模式下只看关注的重要事件
+func hasImportantEvents(snapshot source.Snapshot, ann annotations, mode DisplayMode) bool {
+	if mode != ModeWatchlist {
+		return len(snapshot.ImportantEvents) > 0
+	}
+	for _, e := range snapshot.ImportantEvents {
+		if ann.watched[eventKey(e.Time, e.Region, e.Event)] {
+			return true
+		}
+	}
+	return false
+}
+
+// 根据显示模式将一天的数据格式化为列表行，同时返回事件行对应的事件
+func renderRows(snapshot source.Snapshot, ann annotations, mode DisplayMode, cfg *config.AppConfig, termWidth int) ([]string, map[int]rowRef) {
+	separator := strings.Repeat("─", termWidth-2)
+
+	var rows []string
+	refs := make(map[int]rowRef)
+	rows = append(rows, "[=== 财经日历事件 ===](fg:green)")
+	rows = append(rows, eventHeaderRow(cfg))
+	rows = append(rows, separator)
+
+	if len(snapshot.Events) == 0 && snapshot.LayoutChanged() {
+		rows = append(rows, "[数据源页面结构已变化，无法解析财经日历，详见日志](fg:red)")
+	}
+
+	if mode == ModeWatchlist && len(ann.watched) == 0 {
+		rows = append(rows, "[今天没有关注的事件，可在其它模式下按 w 将光标所在事件加入关注](fg:yellow)")
+	}
+
+	loc := cfg.Location()
+	currentTime := ""
+	for i, event := range snapshot.Events {
+		if !visibleEvent(mode, event, ann) {
+			continue
+		}
+
+		eventTime := displayTime(event.Time, event.At, snapshot.Date, loc)
+		if eventTime != currentTime {
+			if currentTime != "" {
+				rows = append(rows, separator)
+			}
+			currentTime = eventTime
+		}
+
+		refs[len(rows)] = rowRef{day: snapshot.Date, event: &snapshot.Events[i]}
+		rows = append(rows, formatEventRow(event, eventTime, ann, cfg))
+	}
+
+	if showsImportantEvents(mode) {
+		if hasImportantEvents(snapshot, ann, mode) {
+			rows = append(rows, "")
+			rows = append(rows, "[=== 重要事件 ===](fg:green)")
+			rows = append(rows, fmt.Sprintf("[%-*s  %-*s  %s](fg:cyan)",
+				cfg.UI.TimeWidth, "时间",
+				cfg.UI.ImportanceWidth, "重要性",
+				"事件"))
+			rows = append(rows, separator)
+
+			for i, event := range snapshot.ImportantEvents {
+				key := eventKey(event.Time, event.Region, event.Event)
+				if visibleImportantEvent(mode, event, ann) {
+					importanceColor := "white"
+					if event.Importance == "高" {
+						importanceColor = "red"
+					}
+					row := fmt.Sprintf("[%-*s](fg:cyan)  [%-*s](fg:%s)  %s",
+						cfg.UI.TimeWidth, displayTime(event.Time, event.At, snapshot.Date, loc),
+						cfg.UI.ImportanceWidth, event.Importance,
+						importanceColor,
+						formatNameCell(event.Event, ann.highlighted[key], ann.watched[key]))
+					refs[len(rows)] = rowRef{day: snapshot.Date, important: &snapshot.ImportantEvents[i]}
+					rows = append(rows, row)
+				}
+			}
+		}
+	}
+
+	if showsRates(mode) {
+		rows = append(rows, "")
+		rows = append(rows, "[=== 央行利率信息 ===](fg:green)")
+		bankWidth := 20
+		rateWidth := 10
+		changeWidth := 8
+		dateWidth := 12
+		historyWidth := 20
+
+		rows = append(rows, fmt.Sprintf("[%-*s  %-*s  %-*s  %-*s  %-*s  %-*s  %-*s](fg:cyan)",
+			bankWidth, "央行/利率类型",
+			rateWidth, "当前利率",
+			rateWidth, "前值",
+			changeWidth, "变动",
+			dateWidth, "变动日期",
+			historyWidth, "历史区间",
+			rateWidth, "下次预测"))
+		rows = append(rows, separator)
+
+		for _, rate := range snapshot.Rates {
+			parts := strings.Split(rate.LastChange, " ")
+			changeDate := ""
+			changeValue := ""
+			if len(parts) >= 2 {
+				changeDate = parts[1]
+				changeValue = parts[0]
+			}
+
+			historyRange := fmt.Sprintf("%s - %s", rate.HistoryLow, rate.HistoryHigh)
+			bankInfo := fmt.Sprintf("%s - %s", rate.Bank, rate.RateName)
+
+			row := fmt.Sprintf("[%-*s](fg:yellow)  [%-*s](fg:green)  [%-*s](fg:white)  [%-*s](fg:red)  [%-*s](fg:cyan)  [%-*s](fg:white)  [%-*s](fg:white)",
+				bankWidth, bankInfo,
+				rateWidth, rate.CurrentRate,
+				rateWidth, rate.PreviousRate,
+				changeWidth, changeValue,
+				dateWidth, changeDate,
+				historyWidth, historyRange,
+				rateWidth, rate.NextForecast)
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		rows = []string{"[暂无数据](fg:red)"}
+	}
+	return rows, refs
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/changes"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/server"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// 注册 serve 命令的参数
+func serveCommand(fs *flag.FlagSet) func(c *cliContext) error {
+	addr := fs.String("addr", "127.0.0.1:8080", "HTTP 监听地址")
+	return func(c *cliContext) error {
+		return runServe(c, *addr)
+	}
+}
+
+// 定时获取当天和未来几天的数据并保存，同时以 HTTP/JSON 提供数据库中的数据
+func runServe(c *cliContext, addr string) error {
+	src, db, err := openData(c.cfg, &htmlfetcher.DefaultFetcher{})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New(db, src.Location())
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// 收到退出信号时结束 /stream 等长连接，使 Shutdown 不必等待超时
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+	go fetchLoop(ctx, src, db, srv, time.Duration(c.cfg.RefreshInterval)*time.Second)
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- httpServer.ListenAndServe()
+	}()
+	logger.Info("HTTP 服务已启动", zap.String("addr", addr))
+	fmt.Fprintf(c.out, "正在监听 http://%s\n", addr)
+
+	select {
+	case err := <-errc:
+		return fmt.Errorf("HTTP 服务失败: %v", err)
+	case <-ctx.Done():
+	}
+
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdown); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	logger.Info("HTTP 服务已停止")
+	return nil
+}
+
+// 未来几天的数据变化较少，每隔 upcomingInterval 获取一次
+const upcomingInterval = time.Hour
+
+// 立即获取一次当天数据，之后每隔 interval 获取一次，直到 ctx 结束。
+// 每次获取与上一次同一天的数据比较，将变化推送给 /stream 的订阅者。
+// 订阅日历默认包含的未来几天按 upcomingInterval 获取并保存，不推送变化
+func fetchLoop(ctx context.Context, src source.Source, db *storage.DB, srv *server.Server, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var last *source.Snapshot
+	var upcomingAt time.Time
+	for {
+		now := time.Now()
+		today := startOfDay(now, src.Location())
+		snapshots, err := src.Fetch(today, today)
+		if err != nil {
+			logger.Error("获取数据失败", zap.Error(err))
+		}
+		for i := range snapshots {
+			snapshot := &snapshots[i]
+			saveSnapshot(db, *snapshot)
+			// 启动后的第一次获取和跨天后没有比较基准，不推送
+			if last != nil {
+				srv.Publish(changes.Diff(*last, *snapshot))
+			}
+			last = snapshot
+		}
+		srv.RecordFetch(time.Now(), err)
+
+		if now.Sub(upcomingAt) >= upcomingInterval {
+			upcomingAt = now
+			if err := fetchUpcoming(src, db, today); err != nil {
+				logger.Error("获取未来几天的数据失败", zap.Error(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 获取并保存 today 之后的 server.DefaultCalendarDays-1 天，使 /calendar.ics 默认的天数都有数据
+func fetchUpcoming(src source.Source, db *storage.DB, today time.Time) error {
+	snapshots, err := src.Fetch(today.AddDate(0, 0, 1), today.AddDate(0, 0, server.DefaultCalendarDays-1))
+	if err != nil {
+		return err
+	}
+	for _, snapshot := range snapshots {
+		saveSnapshot(db, snapshot)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// 返回 day 所在周的周一零点
+func startOfWeek(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return time.Date(day.Year(), day.Month(), day.Day()-offset, 0, 0, 0, 0, day.Location())
+}
+
+// 获取周一到周日的数据：一次并发获取整周页面并保存，
+// 数据源失败时逐日退回本地存储
+func loadWeek(src source.Source, db *storage.DB, monday time.Time) ([]source.Snapshot, error) {
+	sunday := monday.AddDate(0, 0, 6)
+	snapshots, err := src.Fetch(monday, sunday)
+	if err == nil {
+		for _, snapshot := range snapshots {
+			saveSnapshot(db, snapshot)
+		}
+		return snapshots, nil
+	}
+
+	logger.Error("获取一周数据失败，使用本地存储", zap.Error(err))
+	found := false
+	snapshots = make([]source.Snapshot, 0, 7)
+	for day := monday; !day.After(sunday); day = day.AddDate(0, 0, 1) {
+		snapshot, ok, _ := storedSnapshot(db, day)
+		if !ok {
+			snapshot = source.Snapshot{Date: day}
+		}
+		found = found || ok
+		snapshots = append(snapshots, snapshot)
+	}
+	if !found {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// 一周视图中日期标题的键
+func dayKey(day time.Time) string {
+	return day.Format("2006-01-02")
+}
+
+// 将一周的数据按日分组格式化为列表行，只显示高重要性事件。
+// 日期标题和事件行都记录所属日期，用于折叠光标所在的日期
+func renderWeekRows(snapshots []source.Snapshot, anns []annotations, collapsed map[string]bool, today time.Time, cfg *config.AppConfig, termWidth int) ([]string, map[int]rowRef) {
+	separator := strings.Repeat("─", termWidth-2)
+	var rows []string
+	refs := make(map[int]rowRef)
+	add := func(row string, ref rowRef) {
+		if !ref.day.IsZero() {
+			refs[len(rows)] = ref
+		}
+		rows = append(rows, row)
+	}
+
+	title := "[=== 一周财经日历 ===](fg:green)"
+	if len(snapshots) > 0 {
+		title = fmt.Sprintf("[=== 一周财经日历 %s ~ %s ===](fg:green)",
+			dayKey(snapshots[0].Date), dayKey(snapshots[len(snapshots)-1].Date))
+	}
+	add(title, rowRef{})
+	add(eventHeaderRow(cfg), rowRef{})
+	add(separator, rowRef{})
+
+	loc := cfg.Location()
+	for i, snapshot := range snapshots {
+		var events []int
+		for j, event := range snapshot.Events {
+			if showEvent(ModeHighImportance, event.Importance) {
+				events = append(events, j)
+			}
+		}
+
+		day := snapshot.Date
+		marker := "▼"
+		if collapsed[dayKey(day)] {
+			marker = "▶"
+		}
+		dayText := fmt.Sprintf("%s %s %s (%d项)", marker, dayKey(day), weekdayNames[day.Weekday()], len(events))
+		if day.Equal(today) {
+			dayText += " 今天"
+		}
+		add(fmt.Sprintf("[%s](fg:yellow)", dayText), rowRef{day: day})
+		if collapsed[dayKey(day)] {
+			continue
+		}
+
+		if len(events) == 0 {
+			if len(snapshot.Events) == 0 && snapshot.LayoutChanged() {
+				add("  [数据源页面结构已变化，无法解析财经日历，详见日志](fg:red)", rowRef{day: day})
+			} else {
+				add("  [无高重要性事件](fg:white)", rowRef{day: day})
+			}
+			continue
+		}
+		for _, j := range events {
+			event := snapshot.Events[j]
+			eventTime := displayTime(event.Time, event.At, day, loc)
+			add(formatEventRow(event, eventTime, anns[i], cfg), rowRef{day: day, event: &snapshot.Events[j]})
+		}
+	}
+	return rows, refs
+}
@@ -0,0 +1,95 @@
+// Package changes 比较同一天相邻两次获取的数据，找出新公布的数值、
+// 被修正的数值、央行利率变化和新出现的重要事件
+package changes
+
+import (
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+)
+
+// Kind 表示变化的类型
+type Kind string
+
+const (
+	KindReleased       Kind = "released"        // 财经日历事件的公布值出现
+	KindRevised        Kind = "revised"         // 前值或已公布的数值被修正
+	KindRateChanged    Kind = "rate_changed"    // 央行利率的当前值变化或出现新的利率
+	KindImportantEvent Kind = "important_event" // 出现新的重要事件
+)
+
+// Change 是一次变化，Event、ImportantEvent、Rate 只设置其一
+type Change struct {
+	Kind           Kind                    `json:"kind"`
+	Date           string                  `json:"date"` // YYYY-MM-DD
+	Event          *parser.CalendarEvent   `json:"event,omitempty"`
+	ImportantEvent *parser.ImportantEvent  `json:"important_event,omitempty"`
+	Rate           *parser.CentralBankRate `json:"rate,omitempty"`
+	Field          string                  `json:"field,omitempty"` // 发生变化的字段: previous actual current_rate
+	Old            string                  `json:"old,omitempty"`
+	New            string                  `json:"new,omitempty"`
+}
+
+func eventKey(e parser.CalendarEvent) string {
+	return e.Time + "|" + e.Region + "|" + e.Indicator
+}
+
+func importantKey(e parser.ImportantEvent) string {
+	return e.Time + "|" + e.Region + "|" + e.Event
+}
+
+func rateKey(r parser.CentralBankRate) string {
+	return r.Bank + "|" + r.RateName
+}
+
+// Diff 返回从 prev 到 next 的变化，按 next 中的顺序排列。
+// 两次数据不是同一天时没有可比较的基准，返回 nil
+func Diff(prev, next source.Snapshot) []Change {
+	if !prev.Date.Equal(next.Date) {
+		return nil
+	}
+	date := next.Date.Format("2006-01-02")
+	var changes []Change
+
+	oldEvents := make(map[string]parser.CalendarEvent, len(prev.Events))
+	for _, e := range prev.Events {
+		oldEvents[eventKey(e)] = e
+	}
+	for i := range next.Events {
+		e := &next.Events[i]
+		old, seen := oldEvents[eventKey(*e)]
+		// 数值变为空（例如页面暂时缺失）不算修正
+		switch {
+		case e.Actual != "" && old.Actual == "":
+			// 包括两次获取之间新出现且已公布的事件
+			changes = append(changes, Change{Kind: KindReleased, Date: date, Event: e, Field: "actual", New: e.Actual})
+		case seen && e.Actual != "" && e.Actual != old.Actual:
+			changes = append(changes, Change{Kind: KindRevised, Date: date, Event: e, Field: "actual", Old: old.Actual, New: e.Actual})
+		}
+		if seen && old.Previous != "" && e.Previous != "" && e.Previous != old.Previous {
+			changes = append(changes, Change{Kind: KindRevised, Date: date, Event: e, Field: "previous", Old: old.Previous, New: e.Previous})
+		}
+	}
+
+	oldImportant := make(map[string]bool, len(prev.ImportantEvents))
+	for _, e := range prev.ImportantEvents {
+		oldImportant[importantKey(e)] = true
+	}
+	for i := range next.ImportantEvents {
+		e := &next.ImportantEvents[i]
+		if !oldImportant[importantKey(*e)] {
+			changes = append(changes, Change{Kind: KindImportantEvent, Date: date, ImportantEvent: e})
+		}
+	}
+
+	oldRates := make(map[string]parser.CentralBankRate, len(prev.Rates))
+	for _, r := range prev.Rates {
+		oldRates[rateKey(r)] = r
+	}
+	for i := range next.Rates {
+		r := &next.Rates[i]
+		if old, seen := oldRates[rateKey(*r)]; !seen || old.CurrentRate != r.CurrentRate {
+			changes = append(changes, Change{Kind: KindRateChanged, Date: date, Rate: r, Field: "current_rate", Old: old.CurrentRate, New: r.CurrentRate})
+		}
+	}
+	return changes
+}
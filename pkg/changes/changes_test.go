@@ -0,0 +1,99 @@
+package changes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+)
+
+var day = time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC)
+
+func snapshot(events []parser.CalendarEvent, important []parser.ImportantEvent, rates []parser.CentralBankRate) source.Snapshot {
+	return source.Snapshot{Source: "fx678", Date: day, Events: events, ImportantEvents: important, Rates: rates}
+}
+
+func TestDiff(t *testing.T) {
+	prev := snapshot(
+		[]parser.CalendarEvent{
+			{Time: "21:15", Region: "美国", Indicator: "1月ADP就业人数(万)", Previous: "12.2", Forecast: "15"},
+			{Time: "21:30", Region: "美国", Indicator: "12月贸易帐(亿美元)", Previous: "-784", Forecast: "-960", Actual: "-980"},
+			{Time: "23:00", Region: "美国", Indicator: "1月ISM非制造业PMI", Forecast: "54.3"},
+		},
+		[]parser.ImportantEvent{{Time: "17:00", Region: "欧盟", Event: "欧盟财长会议"}},
+		[]parser.CentralBankRate{{Bank: "美联储", RateName: "联邦基金利率", CurrentRate: "4.50%"}},
+	)
+	next := snapshot(
+		[]parser.CalendarEvent{
+			{Time: "21:15", Region: "美国", Indicator: "1月ADP就业人数(万)", Previous: "12.2", Forecast: "15", Actual: "18.3"},
+			{Time: "21:30", Region: "美国", Indicator: "12月贸易帐(亿美元)", Previous: "-781", Forecast: "-960", Actual: "-984"},
+			{Time: "23:00", Region: "美国", Indicator: "1月ISM非制造业PMI", Previous: "54.1", Forecast: "54.3"},
+			{Time: "23:30", Region: "美国", Indicator: "EIA原油库存(万桶)", Actual: "866.4"},
+		},
+		[]parser.ImportantEvent{
+			{Time: "17:00", Region: "欧盟", Event: "欧盟财长会议"},
+			{Time: "22:00", Region: "美国", Event: "美联储官员讲话"},
+		},
+		[]parser.CentralBankRate{
+			{Bank: "美联储", RateName: "联邦基金利率", CurrentRate: "4.25%"},
+			{Bank: "日本央行", RateName: "无担保隔夜拆借利率", CurrentRate: "0.50%"},
+		},
+	)
+
+	want := []struct {
+		kind     Kind
+		field    string
+		old, new string
+	}{
+		{KindReleased, "actual", "", "18.3"},
+		{KindRevised, "actual", "-980", "-984"},
+		{KindRevised, "previous", "-784", "-781"},
+		{KindReleased, "actual", "", "866.4"},
+		{KindImportantEvent, "", "", ""},
+		{KindRateChanged, "current_rate", "4.50%", "4.25%"},
+		{KindRateChanged, "current_rate", "", "0.50%"},
+	}
+	got := Diff(prev, next)
+	if len(got) != len(want) {
+		t.Fatalf("变化数量 %d, 期望 %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		c := got[i]
+		if c.Kind != w.kind || c.Field != w.field || c.Old != w.old || c.New != w.new || c.Date != "2025-02-05" {
+			t.Errorf("第%d个变化 = %+v, 期望 %+v", i, c, w)
+		}
+	}
+	if got[4].ImportantEvent == nil || got[4].ImportantEvent.Event != "美联储官员讲话" {
+		t.Errorf("新重要事件: %+v", got[4])
+	}
+}
+
+func TestDiffIgnoresClearedValues(t *testing.T) {
+	prev := snapshot([]parser.CalendarEvent{
+		{Time: "21:30", Region: "美国", Indicator: "12月贸易帐(亿美元)", Previous: "-784", Forecast: "-960", Actual: "-980"},
+	}, nil, nil)
+	next := snapshot([]parser.CalendarEvent{
+		{Time: "21:30", Region: "美国", Indicator: "12月贸易帐(亿美元)", Forecast: "-960"},
+	}, nil, nil)
+	if got := Diff(prev, next); len(got) != 0 {
+		t.Errorf("数值变为空不应算作修正: %+v", got)
+	}
+}
+
+func TestDiffNoChanges(t *testing.T) {
+	s := snapshot(
+		[]parser.CalendarEvent{{Time: "21:15", Region: "美国", Indicator: "1月ADP就业人数(万)", Actual: "18.3"}},
+		[]parser.ImportantEvent{{Time: "17:00", Region: "欧盟", Event: "欧盟财长会议"}},
+		[]parser.CentralBankRate{{Bank: "美联储", RateName: "联邦基金利率", CurrentRate: "4.50%"}},
+	)
+	if got := Diff(s, s); len(got) != 0 {
+		t.Errorf("相同数据不应有变化: %+v", got)
+	}
+
+	other := s
+	other.Date = day.AddDate(0, 0, 1)
+	if got := Diff(s, other); got != nil {
+		t.Errorf("不同日期不应比较: %+v", got)
+	}
+}
@@ -1,30 +1,229 @@
+// Package config 加载并校验程序配置。配置按以下顺序分层覆盖：
+// 默认值 → 系统配置文件 → 用户配置文件 → 当前目录配置文件 → 环境变量 → 命令行参数
 package config
 
 import (
+	"errors"
 	"fmt"
-	"gopkg.in/yaml.v2"
-	"os"
+	"strings"
 	"time"
 )
 
+// DisplayModeCount 界面显示模式的数量，default_display_mode 的取值为 0 ~ DisplayModeCount-1
+const DisplayModeCount = 6
+
+// DisplayModeNames 各显示模式的名称，--mode 参数可以用名称代替编号
+var DisplayModeNames = [DisplayModeCount]string{"high", "all", "rates", "important", "week", "watchlist"}
+
+// AppConfig 程序配置
 type AppConfig struct {
-	TargetURL         string        `yaml:"target_url"`
-	FetchInterval     time.Duration `yaml:"fetch_interval"`
-	MaxDisplay        int           `yaml:"max_display"`
-	DatabasePath      string        `yaml:"database_path"`
-	LogPath           string        `yaml:"log_path"`
-	NotificationMethods []string    `yaml:"notification_methods"`
+	// 刷新间隔（秒）
+	RefreshInterval    int      `yaml:"refresh_interval"`
+	DefaultDisplayMode int      `yaml:"default_display_mode"`
+	Sources            []string `yaml:"sources"`
+	// 解析器列名别名：标准列名 -> 页面上的其它写法
+	ColumnAliases map[string][]string `yaml:"column_aliases"`
+	// 显示时区（IANA名称），为空时使用本地时区
+	Timezone string `yaml:"timezone"`
+	// 关注列表文件
+	Watchlist    string   `yaml:"watchlist"`
+	DatabasePath string   `yaml:"database_path"`
+	LogPath      string   `yaml:"log_path"`
+	UI           UIConfig `yaml:"ui"`
+	// 界面中按 e 导出当前视图的设置
+	Export ExportConfig `yaml:"export"`
+
+	NotificationMethods []string           `yaml:"notification_methods"`
+	Notification        NotificationConfig `yaml:"notification"`
+	Rules               []RuleConfig       `yaml:"rules"`
+
+	// 实际读取的配置文件，按加载顺序
+	Files []string `yaml:"-"`
+
+	location *time.Location
 }
 
-func LoadConfig(path string) (*AppConfig, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+// UIConfig 界面列宽设置
+type UIConfig struct {
+	TimeWidth       int `yaml:"time_width"`
+	ImportanceWidth int `yaml:"importance_width"`
+	ValueWidth      int `yaml:"value_width"` // 前值、预测、公布值
+}
+
+// ExportConfig 导出设置
+type ExportConfig struct {
+	Dir    string `yaml:"dir"`    // 导出文件所在目录
+	Format string `yaml:"format"` // csv jsonl xlsx
+	BOM    bool   `yaml:"bom"`    // CSV 开头写入 UTF-8 BOM，便于 Excel 识别中文
+}
+
+// NotificationConfig 提醒渠道的设置
+type NotificationConfig struct {
+	// 高重要性事件公布前多少分钟提醒
+	LeadMinutes int `yaml:"lead_minutes"`
+	Desktop     struct {
+		Command string   `yaml:"command"` // 默认 notify-send
+		Args    []string `yaml:"args"`
+	} `yaml:"desktop"`
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+	// 多次重试仍失败的webhook请求写入此文件（JSON Lines）
+	DeadLetter string `yaml:"dead_letter"`
+
+	Email struct {
+		Addr     string   `yaml:"addr"` // SMTP服务器 host:port
+		From     string   `yaml:"from"`
+		To       []string `yaml:"to"`
+		Username string   `yaml:"username"`
+		Password string   `yaml:"password"`
+	} `yaml:"email"`
+}
+
+// WebhookConfig 一个webhook目标
+type WebhookConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Go text/template 格式的JSON请求体，留空时发送默认格式
+	Template string `yaml:"template"`
+	// 发送的提醒类型: upcoming、released、rule，留空时都发送
+	Kinds []string `yaml:"kinds"`
+	// 失败后的重试次数，默认3次
+	Retries int `yaml:"retries"`
+	// 首次重试前的等待时间，之后每次加倍，默认1s
+	Backoff time.Duration `yaml:"backoff"`
+}
+
+// RuleConfig 一条提醒规则，所有设置了的条件都满足时触发
+type RuleConfig struct {
+	Name       string   `yaml:"name"`
+	Regions    []string `yaml:"regions"`    // 地区，例如 美国
+	Indicator  string   `yaml:"indicator"`  // 指标或事件名称的正则表达式
+	Importance []string `yaml:"importance"` // 重要性: 高 中 低
+	Impact     string   `yaml:"impact"`     // 影响方向: 利多 利空
+	// 意外值Z分数绝对值的下限，没有Z分数时比较意外值绝对值
+	Surprise float64 `yaml:"surprise"`
+	// 公布时间窗口（显示时区），例如 "20:00-23:00"
+	Window  string   `yaml:"window"`
+	Actions []string `yaml:"actions"` // highlight sound notify log
+}
+
+// Default 返回默认配置
+func Default() *AppConfig {
+	cfg := &AppConfig{
+		RefreshInterval: 15,
+		Sources:         []string{"fx678"},
+		Watchlist:       "watchlist.yaml",
+		DatabasePath:    "data/fmt.db",
+		LogPath:         "logs/app.log",
+		UI: UIConfig{
+			TimeWidth:       6,
+			ImportanceWidth: 6,
+			ValueWidth:      12,
+		},
+		Export: ExportConfig{
+			Dir:    "exports",
+			Format: "csv",
+			BOM:    true,
+		},
 	}
+	cfg.Notification.LeadMinutes = 5
+	return cfg
+}
+
+// Location 返回显示时区，未设置或尚未校验时为本地时区
+func (c *AppConfig) Location() *time.Location {
+	if c.location == nil {
+		return time.Local
+	}
+	return c.location
+}
+
+// 可用的提醒方式
+var notificationMethods = map[string]bool{"console": true, "desktop": true, "webhook": true, "email": true}
 
-	var cfg AppConfig
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %v", err)
+// 可用的导出格式
+var exportFormats = map[string]bool{"csv": true, "jsonl": true, "xlsx": true}
+
+// Validate 校验配置并解析时区，返回列出全部问题的错误
+func (c *AppConfig) Validate() error {
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if c.RefreshInterval <= 0 {
+		fail("refresh_interval 必须大于0（秒），当前为 %d", c.RefreshInterval)
+	}
+	if c.DefaultDisplayMode < 0 || c.DefaultDisplayMode >= DisplayModeCount {
+		fail("default_display_mode 必须在 0 ~ %d 之间，当前为 %d", DisplayModeCount-1, c.DefaultDisplayMode)
+	}
+	if len(c.Sources) == 0 {
+		fail("sources 至少需要一个数据源")
+	}
+	widths := []struct {
+		name  string
+		value int
+	}{
+		{"ui.time_width", c.UI.TimeWidth},
+		{"ui.importance_width", c.UI.ImportanceWidth},
+		{"ui.value_width", c.UI.ValueWidth},
+	}
+	for _, w := range widths {
+		if w.value <= 0 {
+			fail("%s 必须大于0，当前为 %d", w.name, w.value)
+		}
+	}
+	paths := []struct {
+		name  string
+		value string
+	}{
+		{"watchlist", c.Watchlist},
+		{"database_path", c.DatabasePath},
+		{"log_path", c.LogPath},
+		{"export.dir", c.Export.Dir},
+	}
+	for _, p := range paths {
+		if strings.TrimSpace(p.value) == "" {
+			fail("%s 不能为空", p.name)
+		}
+	}
+
+	if !exportFormats[c.Export.Format] {
+		fail("export.format 未知的导出格式 %q（可选 csv、jsonl、xlsx）", c.Export.Format)
+	}
+
+	c.location = nil
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			fail("timezone 无效 %q: %v", c.Timezone, err)
+		} else {
+			c.location = loc
+		}
+	}
+
+	for _, method := range c.NotificationMethods {
+		if !notificationMethods[method] {
+			fail("notification_methods 包含未知的提醒方式 %q（可选 console、desktop、webhook、email）", method)
+		}
+	}
+	if c.Notification.LeadMinutes < 0 {
+		fail("notification.lead_minutes 不能为负数，当前为 %d", c.Notification.LeadMinutes)
+	}
+	for i, w := range c.Notification.Webhooks {
+		if w.URL == "" {
+			fail("notification.webhooks[%d] 缺少 url", i)
+		}
+		if w.Retries < 0 || w.Backoff < 0 {
+			fail("notification.webhooks[%d] 的 retries 和 backoff 不能为负数", i)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	msg := "配置无效:\n  - " + strings.Join(problems, "\n  - ")
+	if len(c.Files) > 0 {
+		msg += "\n已读取的配置文件: " + strings.Join(c.Files, ", ")
 	}
-	return &cfg, nil
+	return errors.New(msg)
 }
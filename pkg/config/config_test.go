@@ -0,0 +1,261 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	system := filepath.Join(dir, "system.yaml")
+	user := filepath.Join(dir, "user.yaml")
+	local := filepath.Join(dir, "config.yaml")
+	writeFile(t, system, "refresh_interval: 30\ntimezone: Europe/London\nui:\n  time_width: 8\n")
+	writeFile(t, user, "refresh_interval: 20\nsources: [fx678]\nui:\n  value_width: 10\n")
+	writeFile(t, local, "refresh_interval: 10\nwatchlist: local.yaml\n")
+
+	fs := flag.NewFlagSet("fmcl", flag.ContinueOnError)
+	flags := BindFlags(fs)
+	if err := fs.Parse([]string{"-mode", "3", "-watchlist", "flag.yaml"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(Options{
+		SystemFile: system,
+		UserFile:   user,
+		File:       local,
+		Env:        []string{"FMCL_UI_IMPORTANCE_WIDTH=9", "FMCL_NOTIFICATION_METHODS=console, desktop", "FMCL_DEFAULT_DISPLAY_MODE=1"},
+		Flags:      flags,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.RefreshInterval != 10 {
+		t.Errorf("refresh_interval = %d, 当前目录文件应覆盖用户文件和系统文件", cfg.RefreshInterval)
+	}
+	if cfg.UI != (UIConfig{TimeWidth: 8, ImportanceWidth: 9, ValueWidth: 10}) {
+		t.Errorf("ui = %+v", cfg.UI)
+	}
+	if cfg.DefaultDisplayMode != 3 || cfg.Watchlist != "flag.yaml" {
+		t.Errorf("命令行参数应覆盖环境变量和文件: mode=%d watchlist=%s", cfg.DefaultDisplayMode, cfg.Watchlist)
+	}
+	if !reflect.DeepEqual(cfg.NotificationMethods, []string{"console", "desktop"}) {
+		t.Errorf("notification_methods = %q", cfg.NotificationMethods)
+	}
+	if cfg.DatabasePath != "data/fmt.db" || cfg.Notification.LeadMinutes != 5 {
+		t.Errorf("未设置的项应保留默认值: %s %d", cfg.DatabasePath, cfg.Notification.LeadMinutes)
+	}
+	if cfg.Location().String() != "Europe/London" {
+		t.Errorf("时区 = %s", cfg.Location())
+	}
+	if !reflect.DeepEqual(cfg.Files, []string{system, user, local}) {
+		t.Errorf("已读取的配置文件 = %q", cfg.Files)
+	}
+}
+
+func TestModeFlagNames(t *testing.T) {
+	dir := t.TempDir()
+	load := func(mode string) (*AppConfig, error) {
+		fs := flag.NewFlagSet("fmcl", flag.ContinueOnError)
+		flags := BindFlags(fs)
+		if err := fs.Parse([]string{"-mode", mode}); err != nil {
+			t.Fatal(err)
+		}
+		return Load(Options{
+			SystemFile: filepath.Join(dir, "system.yaml"),
+			UserFile:   filepath.Join(dir, "user.yaml"),
+			Env:        []string{},
+			Flags:      flags,
+		})
+	}
+
+	for mode, want := range map[string]int{"week": 4, "Watchlist": 5, "2": 2} {
+		cfg, err := load(mode)
+		if err != nil {
+			t.Fatalf("-mode %s: %v", mode, err)
+		}
+		if cfg.DefaultDisplayMode != want {
+			t.Errorf("-mode %s = %d, 期望 %d", mode, cfg.DefaultDisplayMode, want)
+		}
+	}
+	for _, mode := range []string{"6", "daily"} {
+		if _, err := load(mode); err == nil || !strings.Contains(err.Error(), "mode") {
+			t.Errorf("-mode %s 应返回错误: %v", mode, err)
+		}
+	}
+}
+
+func TestLoadMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	cfg, err := Load(Options{
+		SystemFile: filepath.Join(dir, "system.yaml"),
+		UserFile:   filepath.Join(dir, "user.yaml"),
+		Env:        []string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, func() *AppConfig { c := Default(); c.Validate(); return c }()) {
+		t.Errorf("没有配置文件时应使用默认值: %+v", cfg)
+	}
+
+	// 显式指定的配置文件必须存在
+	_, err = Load(Options{
+		SystemFile: filepath.Join(dir, "system.yaml"),
+		UserFile:   filepath.Join(dir, "user.yaml"),
+		Env:        []string{"FMCL_CONFIG=" + filepath.Join(dir, "missing.yaml")},
+	})
+	if err == nil {
+		t.Error("FMCL_CONFIG 指定的文件不存在时应返回错误")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	load := func(content string, env ...string) error {
+		writeFile(t, path, content)
+		_, err := Load(Options{
+			SystemFile: filepath.Join(dir, "system.yaml"),
+			UserFile:   filepath.Join(dir, "user.yaml"),
+			File:       path,
+			Env:        env,
+		})
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		env     []string
+		want    []string
+	}{
+		{"未知的键", "refresh_intervall: 10\n", nil, []string{path, "refresh_intervall"}},
+		{"类型错误", "refresh_interval: soon\n", nil, []string{path, "soon"}},
+		{"校验失败",
+			"refresh_interval: 0\ndefault_display_mode: 9\nui:\n  value_width: -1\ntimezone: Mars/Base\nnotification_methods: [pager]\nexport:\n  format: xls\n",
+			nil,
+			[]string{"refresh_interval 必须大于0", "default_display_mode 必须在 0 ~ 5 之间，当前为 9",
+				"ui.value_width 必须大于0", "timezone 无效", "未知的提醒方式 \"pager\"", "export.format 未知的导出格式 \"xls\"",
+				"已读取的配置文件: " + path}},
+		{"环境变量类型错误", "", []string{"FMCL_UI_TIME_WIDTH=wide"}, []string{"FMCL_UI_TIME_WIDTH", "需要整数"}},
+	}
+	for _, tt := range tests {
+		err := load(tt.content, tt.env...)
+		if err == nil {
+			t.Errorf("%s: 应返回错误", tt.name)
+			continue
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: 错误信息缺少 %q:\n%v", tt.name, want, err)
+			}
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("notification.desktop.command", "terminal-notifier"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Set("notification.email.to", "a@example.com,b@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Notification.Desktop.Command != "terminal-notifier" || len(cfg.Notification.Email.To) != 2 {
+		t.Errorf("notification = %+v", cfg.Notification)
+	}
+
+	for _, key := range []string{"ui.missing", "ui", "rules", "column_aliases"} {
+		if err := cfg.Set(key, "1"); err == nil {
+			t.Errorf("Set(%q) 应返回错误", key)
+		}
+	}
+}
+
+func TestSetDuration(t *testing.T) {
+	var w struct {
+		Backoff time.Duration `yaml:"backoff"`
+	}
+	v := reflect.ValueOf(&w).Elem().Field(0)
+	if err := setValue(v, "1m30s"); err != nil || w.Backoff != 90*time.Second {
+		t.Errorf("backoff = %v, %v", w.Backoff, err)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	writeFile(t, path, "refresh_interval: 15\n")
+	opts := Options{
+		SystemFile: filepath.Join(dir, "system.yaml"),
+		UserFile:   filepath.Join(dir, "user.yaml"),
+		File:       path,
+		Env:        []string{},
+	}
+	r := NewReloader(opts)
+
+	if _, changed, _ := r.Check(); changed {
+		t.Fatal("文件未修改时不应重新加载")
+	}
+
+	writeFile(t, path, "refresh_interval: 30\nui:\n  value_width: 10\n")
+	cfg, changed, err := r.Check()
+	if !changed || err != nil || cfg.RefreshInterval != 30 || cfg.UI.ValueWidth != 10 {
+		t.Fatalf("修改后 = %+v, %v, %v", cfg, changed, err)
+	}
+
+	writeFile(t, path, "refresh_interval: -30\n")
+	if _, changed, err := r.Check(); !changed || err == nil || !strings.Contains(err.Error(), "refresh_interval 必须大于0") {
+		t.Errorf("无效配置应返回校验错误: %v, %v", changed, err)
+	}
+	if _, changed, _ := r.Check(); changed {
+		t.Error("同一个无效配置不应重复报告")
+	}
+
+	// 新建用户配置文件同样触发重新加载
+	writeFile(t, opts.UserFile, "timezone: Asia/Tokyo\n")
+	writeFile(t, path, "refresh_interval: 20\n")
+	cfg, changed, err = r.Check()
+	if !changed || err != nil || cfg.Location().String() != "Asia/Tokyo" || cfg.RefreshInterval != 20 {
+		t.Errorf("新建用户配置文件后 = %+v, %v, %v", cfg, changed, err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	cfg := Default()
+	next := Default()
+	next.RefreshInterval = 60
+	next.UI.TimeWidth = 9
+	next.Export.Format = "xlsx"
+	next.Timezone = "Asia/Tokyo"
+	next.Sources = []string{"other"}
+	next.NotificationMethods = []string{"console"}
+	if err := next.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys := cfg.Apply(next)
+	if !reflect.DeepEqual(keys, []string{"sources", "notification_methods"}) {
+		t.Errorf("需重启的配置项 = %q", keys)
+	}
+	if cfg.RefreshInterval != 60 || cfg.UI.TimeWidth != 9 || cfg.Export.Format != "xlsx" || cfg.Location().String() != "Asia/Tokyo" {
+		t.Errorf("可热加载的配置项未生效: %+v", cfg)
+	}
+	if cfg.Sources[0] != "fx678" || cfg.NotificationMethods != nil {
+		t.Errorf("需重启的配置项应保持原值: %q %q", cfg.Sources, cfg.NotificationMethods)
+	}
+}
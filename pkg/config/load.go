@@ -0,0 +1,339 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 默认的配置文件位置
+const (
+	SystemFile = "/etc/fmcl/config.yaml"
+	LocalFile  = "config.yaml"
+)
+
+// 环境变量前缀，例如 FMCL_REFRESH_INTERVAL、FMCL_UI_VALUE_WIDTH
+const EnvPrefix = "FMCL_"
+
+// UserFile 返回用户配置文件路径: $XDG_CONFIG_HOME/fmcl/config.yaml，
+// 未设置时为 ~/.config/fmcl/config.yaml
+func UserFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "fmcl", "config.yaml")
+}
+
+// Options 指定各层配置的来源
+type Options struct {
+	SystemFile string // 为空时使用 SystemFile
+	UserFile   string // 为空时使用 UserFile()
+	// 当前目录的配置文件，为空时使用 FMCL_CONFIG 或 LocalFile。
+	// 通过参数或环境变量显式指定时文件必须存在
+	File  string
+	Env   []string // KEY=VALUE 列表，为 nil 时使用 os.Environ()
+	Flags *Flags   // 命令行参数，可为 nil
+}
+
+// Load 按 默认值 → 系统文件 → 用户文件 → 当前目录文件 → 环境变量 → 命令行参数
+// 的顺序合并配置并校验
+func Load(opts Options) (*AppConfig, error) {
+	vars := opts.envVars()
+
+	cfg := Default()
+	for _, l := range opts.layers(vars) {
+		loaded, err := cfg.mergeFile(l.path, l.required)
+		if err != nil {
+			return nil, err
+		}
+		if loaded {
+			cfg.Files = append(cfg.Files, l.path)
+		}
+	}
+
+	for _, key := range leafKeys(reflect.TypeOf(*cfg), "") {
+		name := envName(key)
+		value, ok := vars[name]
+		if !ok {
+			continue
+		}
+		if err := cfg.Set(key, value); err != nil {
+			return nil, fmt.Errorf("环境变量 %s=%q 无效: %v", name, value, err)
+		}
+	}
+
+	if opts.Flags != nil {
+		for _, o := range opts.Flags.values {
+			if err := cfg.Set(o.key, o.value); err != nil {
+				return nil, fmt.Errorf("参数 -%s=%q 无效: %v", o.flag, o.value, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// 以 FMCL_ 开头的环境变量
+func (o Options) envVars() map[string]string {
+	env := o.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	vars := make(map[string]string)
+	for _, kv := range env {
+		if key, value, ok := strings.Cut(kv, "="); ok && strings.HasPrefix(key, EnvPrefix) {
+			vars[key] = value
+		}
+	}
+	return vars
+}
+
+// 一层配置文件
+type layer struct {
+	path     string
+	required bool // 显式指定的文件必须存在
+}
+
+// 按加载顺序列出配置文件
+func (o Options) layers(vars map[string]string) []layer {
+	systemFile := o.SystemFile
+	if systemFile == "" {
+		systemFile = SystemFile
+	}
+	userFile := o.UserFile
+	if userFile == "" {
+		userFile = UserFile()
+	}
+	file, required := o.File, o.File != ""
+	if o.Flags != nil && o.Flags.ConfigFile != "" {
+		file, required = o.Flags.ConfigFile, true
+	}
+	if file == "" {
+		file, required = vars[EnvPrefix+"CONFIG"], vars[EnvPrefix+"CONFIG"] != ""
+	}
+	if file == "" {
+		file = LocalFile
+	}
+
+	var layers []layer
+	for _, l := range []layer{{systemFile, false}, {userFile, false}, {file, required}} {
+		if l.path != "" {
+			layers = append(layers, l)
+		}
+	}
+	return layers
+}
+
+// 将配置文件合并到 c，文件中未出现的键保留原值，未知的键报错。
+// 返回文件是否存在
+func (c *AppConfig) mergeFile(path string, required bool) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) && !required {
+			return false, nil
+		}
+		return false, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("解析配置文件 %s 失败: %v", path, err)
+	}
+	return true, nil
+}
+
+// 环境变量名: FMCL_ + 大写的键路径，"." 替换为 "_"
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// 列出可以用环境变量设置的键，即标量和字符串列表字段
+func leafKeys(t reflect.Type, prefix string) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := yamlName(field)
+		if name == "" {
+			continue
+		}
+		key := prefix + name
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			keys = append(keys, leafKeys(field.Type, key+".")...)
+		case settable(field.Type):
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// 字段的 yaml 键名，不参与配置的字段返回空串
+func yamlName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// 是否可以用字符串设置
+func settable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Int, reflect.Int64, reflect.Float64, reflect.Bool:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// Set 按键路径（例如 ui.value_width）用字符串设置配置项，
+// 字符串列表用逗号分隔，时长使用 Go 时长格式（例如 1m30s）
+func (c *AppConfig) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		field, ok := fieldByName(v, part)
+		if !ok {
+			return fmt.Errorf("未知的配置项 %s", key)
+		}
+		v = field
+		if i < len(parts)-1 && v.Kind() != reflect.Struct {
+			return fmt.Errorf("未知的配置项 %s", key)
+		}
+	}
+	if !settable(v.Type()) {
+		return fmt.Errorf("配置项 %s 不能通过字符串设置", key)
+	}
+	return setValue(v, value)
+}
+
+// 按 yaml 键名查找结构体字段
+func fieldByName(v reflect.Value, name string) (reflect.Value, bool) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		if yamlName(v.Type().Field(i)) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+func setValue(v reflect.Value, value string) error {
+	value = strings.TrimSpace(value)
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("需要时长，例如 30s")
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Int || v.Kind() == reflect.Int64:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("需要整数")
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("需要数字")
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("需要 true 或 false")
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice:
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
+
+// 命令行参数与配置项的对应关系
+var flagBindings = []struct {
+	flag  string
+	key   string
+	usage string
+	parse func(string) string // 可选，把参数值转换为配置项的值
+}{
+	{"refresh-interval", "refresh_interval", "刷新间隔（秒）", nil},
+	{"mode", "default_display_mode", fmt.Sprintf("默认显示模式: 0-%d 或 %s", DisplayModeCount-1, strings.Join(DisplayModeNames[:], " ")), displayModeNumber},
+	{"sources", "sources", "数据源，逗号分隔", nil},
+	{"timezone", "timezone", "显示时区（IANA名称）", nil},
+	{"watchlist", "watchlist", "关注列表文件", nil},
+	{"db", "database_path", "数据库文件", nil},
+	{"log", "log_path", "日志文件", nil},
+	{"notify", "notification_methods", "提醒方式，逗号分隔: console desktop webhook email", nil},
+}
+
+// 将显示模式名称转换为编号，其它值原样返回，由 Validate 检查范围
+func displayModeNumber(value string) string {
+	for i, name := range DisplayModeNames {
+		if strings.EqualFold(strings.TrimSpace(value), name) {
+			return strconv.Itoa(i)
+		}
+	}
+	return value
+}
+
+// 一个命令行参数设置的值
+type override struct {
+	flag, key, value string
+}
+
+// Flags 记录命令行中设置的配置项，只有显式给出的参数会覆盖其它层
+type Flags struct {
+	ConfigFile string // --config 指定的配置文件
+
+	values []override
+}
+
+// BindFlags 在 fs 上注册 --config 和各配置项对应的参数
+func BindFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringVar(&f.ConfigFile, "config", "", "配置文件（默认 ./config.yaml）")
+	for _, b := range flagBindings {
+		b := b
+		fs.Func(b.flag, b.usage, func(value string) error {
+			if b.parse != nil {
+				value = b.parse(value)
+			}
+			f.values = append(f.values, override{flag: b.flag, key: b.key, value: value})
+			return nil
+		})
+	}
+	return f
+}
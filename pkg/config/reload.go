@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"time"
+)
+
+// DefaultPollInterval 检查配置文件是否修改的默认间隔
+const DefaultPollInterval = 2 * time.Second
+
+// 配置文件的修改时间和大小，文件不存在时为零值
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader 轮询各层配置文件的修改时间，文件被修改、创建或删除时重新加载配置
+type Reloader struct {
+	Options Options
+
+	stamps map[string]stamp
+}
+
+// NewReloader 记录各层配置文件的当前状态，之后的 Check 与之比较
+func NewReloader(opts Options) *Reloader {
+	r := &Reloader{Options: opts}
+	r.stamps = r.scan()
+	return r
+}
+
+// 读取各层配置文件的当前状态
+func (r *Reloader) scan() map[string]stamp {
+	stamps := make(map[string]stamp)
+	for _, l := range r.Options.layers(r.Options.envVars()) {
+		var s stamp
+		if info, err := os.Stat(l.path); err == nil {
+			s = stamp{modTime: info.ModTime(), size: info.Size()}
+		}
+		stamps[l.path] = s
+	}
+	return stamps
+}
+
+// Check 检查配置文件是否变化，变化时重新加载并校验。
+// changed 为 false 表示没有变化；重新加载失败时返回 changed 为 true 和错误，
+// 之后文件未再修改时不会重复报告
+func (r *Reloader) Check() (cfg *AppConfig, changed bool, err error) {
+	stamps := r.scan()
+	if sameStamps(stamps, r.stamps) {
+		return nil, false, nil
+	}
+	r.stamps = stamps
+
+	cfg, err = Load(r.Options)
+	return cfg, true, err
+}
+
+func sameStamps(a, b map[string]stamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, s := range a {
+		old, ok := b[path]
+		if !ok || !old.modTime.Equal(s.modTime) || old.size != s.size {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply 将 next 中可以在运行中生效的配置项（刷新间隔、默认显示模式、
+// 界面列宽、显示时区和导出设置）应用到 c，返回有变化但需要重启才能生效的配置项。
+// 需要重启的配置项保持运行中的值，因此之后重新加载不会重复提示
+func (c *AppConfig) Apply(next *AppConfig) []string {
+	restart := []struct {
+		key       string
+		cur, next interface{}
+	}{
+		{"sources", c.Sources, next.Sources},
+		{"column_aliases", c.ColumnAliases, next.ColumnAliases},
+		{"watchlist", c.Watchlist, next.Watchlist},
+		{"database_path", c.DatabasePath, next.DatabasePath},
+		{"log_path", c.LogPath, next.LogPath},
+		{"notification_methods", c.NotificationMethods, next.NotificationMethods},
+		{"notification", c.Notification, next.Notification},
+		{"rules", c.Rules, next.Rules},
+	}
+	var keys []string
+	for _, r := range restart {
+		if !reflect.DeepEqual(r.cur, r.next) {
+			keys = append(keys, r.key)
+		}
+	}
+
+	c.RefreshInterval = next.RefreshInterval
+	c.DefaultDisplayMode = next.DefaultDisplayMode
+	c.UI = next.UI
+	c.Export = next.Export
+	c.Timezone = next.Timezone
+	c.location = next.location
+	c.Files = next.Files
+	return keys
+}
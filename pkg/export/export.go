@@ -0,0 +1,100 @@
+// Package export 将表格数据导出为 CSV、JSON Lines 或 Excel (xlsx) 文件
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 导出格式
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+)
+
+// ParseFormat 检查导出格式，忽略大小写
+func ParseFormat(format string) (string, error) {
+	switch f := strings.ToLower(strings.TrimSpace(format)); f {
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("未知的导出格式 %q（可选 csv、jsonl、xlsx）", format)
+}
+
+// Sheet 是一张表。CSV 和 xlsx 使用 Header 和 Rows，JSON Lines 每行输出 Records 中的一项
+type Sheet struct {
+	Name    string
+	Header  []string
+	Rows    [][]string
+	Records []interface{}
+}
+
+// Options 导出选项
+type Options struct {
+	Format string
+	// CSV 开头写入 UTF-8 BOM，Excel 据此识别中文
+	BOM bool
+}
+
+// Write 按 opts.Format 将 sheets 写入 w
+func Write(w io.Writer, sheets []Sheet, opts Options) error {
+	switch opts.Format {
+	case FormatCSV:
+		return WriteCSV(w, sheets, opts.BOM)
+	case FormatJSONL:
+		return WriteJSONLines(w, sheets)
+	case FormatXLSX:
+		return WriteXLSX(w, sheets)
+	}
+	return fmt.Errorf("未知的导出格式 %q（可选 csv、jsonl、xlsx）", opts.Format)
+}
+
+// utf8BOM 是 UTF-8 的字节顺序标记
+const utf8BOM = "\ufeff"
+
+// WriteCSV 输出 CSV。多张表时每张表前有一行表名，表之间以空行分隔
+func WriteCSV(w io.Writer, sheets []Sheet, bom bool) error {
+	if bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return err
+		}
+	}
+	writer := csv.NewWriter(w)
+	for i, sheet := range sheets {
+		var lines [][]string
+		if len(sheets) > 1 {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			lines = append(lines, []string{sheet.Name})
+		}
+		lines = append(lines, sheet.Header)
+		for _, line := range lines {
+			if err := writer.Write(line); err != nil {
+				return err
+			}
+		}
+		if err := writer.WriteAll(sheet.Rows); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJSONLines 每行输出一个JSON对象
+func WriteJSONLines(w io.Writer, sheets []Sheet) error {
+	encoder := json.NewEncoder(w)
+	for _, sheet := range sheets {
+		for _, record := range sheet.Records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var sheets = []Sheet{
+	{
+		Name:    "财经日历事件",
+		Header:  []string{"时间", "指标", "公布值"},
+		Rows:    [][]string{{"21:15", "1月ADP就业人数(万)", "18.3"}, {"21:30", "贸易帐, 12月", "-9.8%"}},
+		Records: []interface{}{map[string]string{"indicator": "1月ADP就业人数(万)"}, map[string]string{"indicator": "贸易帐, 12月"}},
+	},
+	{
+		Name:    "央行利率",
+		Header:  []string{"央行", "当前值"},
+		Rows:    [][]string{{"美联储", "4.50%"}},
+		Records: []interface{}{map[string]string{"bank": "美联储"}},
+	},
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]string{"csv": FormatCSV, " JSONL ": FormatJSONL, "Xlsx": FormatXLSX} {
+		if got, err := ParseFormat(input); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseFormat("xls"); err == nil {
+		t.Error("未知格式应返回错误")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, sheets[:1], Options{Format: FormatCSV, BOM: true}); err != nil {
+		t.Fatal(err)
+	}
+	want := "\ufeff时间,指标,公布值\n21:15,1月ADP就业人数(万),18.3\n21:30,\"贸易帐, 12月\",-9.8%\n"
+	if b.String() != want {
+		t.Errorf("CSV:\n%q\n期望:\n%q", b.String(), want)
+	}
+
+	b.Reset()
+	if err := WriteCSV(&b, sheets, false); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.String(); !strings.HasPrefix(got, "财经日历事件\n时间,") || !strings.Contains(got, "-9.8%\n\n央行利率\n央行,当前值\n") {
+		t.Errorf("多张表的CSV:\n%s", got)
+	}
+}
+
+// 写入总是失败的输出
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("磁盘已满")
+}
+
+func TestWriteCSVReturnsWriteError(t *testing.T) {
+	if err := WriteCSV(failingWriter{}, sheets, false); err == nil || err.Error() != "磁盘已满" {
+		t.Errorf("应返回写入错误: %v", err)
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, sheets, Options{Format: FormatJSONL}); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"indicator":"1月ADP就业人数(万)"}` + "\n" + `{"indicator":"贸易帐, 12月"}` + "\n" + `{"bank":"美联储"}` + "\n"
+	if b.String() != want {
+		t.Errorf("JSON Lines:\n%s", b.String())
+	}
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, sheets, Options{Format: FormatXLSX}); err != nil {
+		t.Fatal(err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, f := range archive.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(r)
+		r.Close()
+		// 每个部件都应是格式正确的XML
+		decoder := xml.NewDecoder(bytes.NewReader(content))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: %v", f.Name, err)
+			}
+		}
+		files[f.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml", "xl/worksheets/sheet2.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("缺少 %s", name)
+		}
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `<sheet name="财经日历事件" sheetId="1" r:id="rId1"/>`) {
+		t.Errorf("工作表名称: %s", files["xl/workbook.xml"])
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">时间</t></is></c>`,
+		`<c r="C2"><v>18.3</v></c>`,
+		`<c r="C3" t="inlineStr"><is><t xml:space="preserve">-9.8%</t></is></c>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("缺少单元格 %s\n%s", want, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, 期望 %q", index, got, want)
+		}
+	}
+}
+
+func TestSheetNames(t *testing.T) {
+	got := sheetNames([]Sheet{{Name: "a/b"}, {Name: "a/b"}, {}, {Name: strings.Repeat("长", 40)}})
+	want := []string{"a_b", "a_b (2)", "Sheet3", strings.Repeat("长", 31)}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("sheetNames = %q", got)
+	}
+}
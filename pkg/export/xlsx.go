@@ -0,0 +1,178 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// xlsx 是 zip 压缩的 Office Open XML 文件，这里只写出 Excel 打开所需的最少部件：
+// 每张表一个工作表，单元格使用内联字符串，表头加粗并冻结
+const (
+	contentTypesHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`
+
+	rootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	// 字体 0 为正文，字体 1 加粗；单元格样式 1 用于表头
+	styles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+
+	relationshipsNS = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	spreadsheetNS   = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+)
+
+// zip 中的一个文件
+type part struct {
+	name    string
+	content []byte
+}
+
+// WriteXLSX 输出 Excel 工作簿，每张表一个工作表
+func WriteXLSX(w io.Writer, sheets []Sheet) error {
+	if len(sheets) == 0 {
+		sheets = []Sheet{{}}
+	}
+	names := sheetNames(sheets)
+
+	var contentTypes, workbook, workbookRels bytes.Buffer
+	contentTypes.WriteString(contentTypesHeader)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	fmt.Fprintf(&workbook, `<workbook xmlns="%s" xmlns:r="%s"><sheets>`, spreadsheetNS, relationshipsNS)
+	workbookRels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	workbookRels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escapeXML(names[i]), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, n, relationshipsNS, n)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(sheets)+1, relationshipsNS)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []part{
+		{"[Content_Types].xml", contentTypes.Bytes()},
+		{"_rels/.rels", []byte(rootRels)},
+		{"xl/workbook.xml", workbook.Bytes()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.Bytes()},
+		{"xl/styles.xml", []byte(styles)},
+	}
+	for i, sheet := range sheets {
+		parts = append(parts, part{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(sheet)})
+	}
+
+	archive := zip.NewWriter(w)
+	for _, p := range parts {
+		f, err := archive.Create(p.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(p.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// 一个工作表，第一行为表头
+func worksheet(sheet Sheet) []byte {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	fmt.Fprintf(&b, `<worksheet xmlns="%s">`, spreadsheetNS)
+	if len(sheet.Header) > 0 {
+		b.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+			`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>` +
+			`</sheetView></sheetViews>`)
+	}
+	b.WriteString(`<sheetData>`)
+	rows := sheet.Rows
+	if len(sheet.Header) > 0 {
+		rows = append([][]string{sheet.Header}, rows...)
+	}
+	for r, row := range rows {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, value := range row {
+			ref := columnName(c) + fmt.Sprint(r+1)
+			style := ""
+			if r == 0 && len(sheet.Header) > 0 {
+				style = ` s="1"`
+			}
+			if r > 0 && number.MatchString(value) {
+				fmt.Fprintf(&b, `<c r="%s"%s><v>%s</v></c>`, ref, style, value)
+				continue
+			}
+			fmt.Fprintf(&b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escapeXML(value))
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.Bytes()
+}
+
+// 写为数值的单元格：不带单位的普通小数，不含前导零，以免改变原文
+var number = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?$`)
+
+// 列号转为列名: 0 -> A, 25 -> Z, 26 -> AA
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// 工作表名称不能为空、重复、超过31个字符或包含 []:*?/\
+func sheetNames(sheets []Sheet) []string {
+	invalid := strings.NewReplacer("[", "_", "]", "_", ":", "_", "*", "_", "?", "_", "/", "_", `\`, "_")
+	used := make(map[string]bool)
+	names := make([]string, len(sheets))
+	for i, sheet := range sheets {
+		base := invalid.Replace(sheet.Name)
+		if utf8.RuneCountInString(base) > 31 {
+			base = string([]rune(base)[:31])
+		}
+		if base == "" {
+			base = fmt.Sprintf("Sheet%d", i+1)
+		}
+		name := base
+		for n := 2; used[name]; n++ {
+			suffix := fmt.Sprintf(" (%d)", n)
+			runes := []rune(base)
+			if len(runes)+len([]rune(suffix)) > 31 {
+				runes = runes[:31-len([]rune(suffix))]
+			}
+			name = string(runes) + suffix
+		}
+		used[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+func escapeXML(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
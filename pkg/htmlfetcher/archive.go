@@ -0,0 +1,224 @@
+package htmlfetcher
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/logger"
+)
+
+// 归档文件名中的时间格式
+const archiveTimeLayout = "20060102T150405.000000000"
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ArchivingFetcher 包装另一个Fetcher，将每次下载的原始页面按日期压缩归档
+type ArchivingFetcher struct {
+	Fetcher Fetcher
+	Dir     string
+}
+
+// NewArchivingFetcher 返回一个将页面归档到 dir 的获取器
+func NewArchivingFetcher(fetcher Fetcher, dir string) *ArchivingFetcher {
+	return &ArchivingFetcher{Fetcher: fetcher, Dir: dir}
+}
+
+// Fetch 获取页面并写入归档，归档失败不影响返回结果
+func (f *ArchivingFetcher) Fetch(url string) (string, error) {
+	html, err := f.Fetcher.Fetch(url)
+	if err != nil {
+		return "", err
+	}
+	if err := WriteArchive(f.Dir, url, html, time.Now()); err != nil {
+		logger.Error("归档页面失败", zap.String("url", url), zap.Error(err))
+	}
+	return html, nil
+}
+
+// Post 直接转发给被包装的获取器，不做归档
+func (f *ArchivingFetcher) Post(url string, data url.Values) (string, error) {
+	return f.Fetcher.Post(url, data)
+}
+
+// WriteArchive 将页面写入 dir/YYYYMMDD/<时间>-<名称>.html.gz，
+// 原始URL和抓取时间记录在gzip头中
+func WriteArchive(dir, pageURL, html string, capturedAt time.Time) error {
+	dayDir := filepath.Join(dir, capturedAt.Format("20060102"))
+	if err := os.MkdirAll(dayDir, 0755); err != nil {
+		return err
+	}
+
+	name := unsafeNameChars.ReplaceAllString(filepath.Base(pageURL), "_")
+	path := filepath.Join(dayDir, fmt.Sprintf("%s-%s.gz", capturedAt.Format(archiveTimeLayout), name))
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := gzip.NewWriter(file)
+	zw.Comment = pageURL
+	zw.ModTime = capturedAt
+	if _, err := io.WriteString(zw, html); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// ArchivedPage 表示归档中的一个页面快照
+type ArchivedPage struct {
+	URL        string
+	CapturedAt time.Time
+	Path       string
+}
+
+// Read 读取并解压页面内容
+func (p ArchivedPage) Read() (string, error) {
+	file, err := os.Open(p.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		return "", err
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ListArchive 返回归档目录中的全部页面，按抓取时间排序
+func ListArchive(dir string) ([]ArchivedPage, error) {
+	var pages []ArchivedPage
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".gz") {
+			return nil
+		}
+
+		stamp, _, _ := strings.Cut(info.Name(), "-")
+		capturedAt, err := time.ParseInLocation(archiveTimeLayout, stamp, time.Local)
+		if err != nil {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		zr, err := gzip.NewReader(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("读取归档文件 %s 失败: %v", path, err)
+		}
+
+		pages = append(pages, ArchivedPage{
+			URL:        zr.Comment,
+			CapturedAt: capturedAt,
+			Path:       path,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].CapturedAt.Before(pages[j].CapturedAt)
+	})
+	return pages, nil
+}
+
+// Replayer 从归档中回放页面，实现Fetcher接口。
+// 回放使用虚拟时钟：从第一个快照的抓取时间开始，按 speed 倍速前进，
+// Fetch 返回该URL在虚拟时间之前最近的一次快照。
+type Replayer struct {
+	mu      sync.Mutex
+	pages   []ArchivedPage
+	speed   float64
+	origin  time.Time
+	started time.Time
+}
+
+// NewReplayer 加载归档目录，speed 为回放倍速（1 为真实速度）
+func NewReplayer(dir string, speed float64) (*Replayer, error) {
+	if speed <= 0 {
+		return nil, fmt.Errorf("回放倍速必须大于0: %v", speed)
+	}
+
+	pages, err := ListArchive(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("归档目录中没有页面: %s", dir)
+	}
+
+	return &Replayer{
+		pages:   pages,
+		speed:   speed,
+		origin:  pages[0].CapturedAt,
+		started: time.Now(),
+	}, nil
+}
+
+// Now 返回回放的虚拟时间
+func (r *Replayer) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elapsed := time.Duration(float64(time.Since(r.started)) * r.speed)
+	return r.origin.Add(elapsed)
+}
+
+// Done 判断虚拟时间是否已越过最后一个快照
+func (r *Replayer) Done() bool {
+	return r.Now().After(r.pages[len(r.pages)-1].CapturedAt)
+}
+
+// Fetch 返回虚拟时间之前该URL最近的一次快照
+func (r *Replayer) Fetch(url string) (string, error) {
+	now := r.Now()
+
+	var found *ArchivedPage
+	for i := range r.pages {
+		page := &r.pages[i]
+		if page.CapturedAt.After(now) {
+			break
+		}
+		if page.URL == url {
+			found = page
+		}
+	}
+	if found == nil {
+		return "", fmt.Errorf("归档中没有 %s 在 %s 之前的快照", url, now.Format("2006-01-02 15:04:05"))
+	}
+	return found.Read()
+}
+
+// Post 回放模式不支持POST请求
+func (r *Replayer) Post(url string, data url.Values) (string, error) {
+	return "", fmt.Errorf("回放模式不支持POST请求: %s", url)
+}
@@ -0,0 +1,168 @@
+package htmlfetcher
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/htmlfetcher/htmlfetchertest"
+)
+
+const (
+	calendarURL  = "https://rl.fx678.com/date/20250205.html"
+	importantURL = "https://rl.fx678.com/date/20250207.html"
+)
+
+func TestWriteAndListArchive(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2025, 2, 5, 21, 29, 58, 0, time.Local)
+	second := first.Add(2 * time.Second)
+	nextDay := first.AddDate(0, 0, 1)
+
+	// 写入顺序与抓取时间无关
+	for _, page := range []struct {
+		url, html string
+		at        time.Time
+	}{
+		{calendarURL, "<html>公布后</html>", second},
+		{importantURL, "<html>次日</html>", nextDay},
+		{calendarURL, "<html>公布前</html>", first},
+	} {
+		if err := WriteArchive(dir, page.url, page.html, page.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "20250206")); err != nil {
+		t.Errorf("归档应按抓取日期分目录: %v", err)
+	}
+
+	pages, err := ListArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("归档页面数 = %d, 期望 3", len(pages))
+	}
+	want := []struct {
+		url, html string
+		at        time.Time
+	}{
+		{calendarURL, "<html>公布前</html>", first},
+		{calendarURL, "<html>公布后</html>", second},
+		{importantURL, "<html>次日</html>", nextDay},
+	}
+	for i, w := range want {
+		if pages[i].URL != w.url || !pages[i].CapturedAt.Equal(w.at) {
+			t.Errorf("第%d个页面 = %s @ %s, 期望 %s @ %s", i, pages[i].URL, pages[i].CapturedAt, w.url, w.at)
+		}
+		html, err := pages[i].Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if html != w.html {
+			t.Errorf("第%d个页面内容 = %q, 期望 %q", i, html, w.html)
+		}
+	}
+}
+
+func TestArchivingFetcher(t *testing.T) {
+	server := htmlfetchertest.NewServer(filepath.Join("..", "..", "testdata", "fx678"))
+	t.Cleanup(server.Close)
+	dir := t.TempDir()
+
+	fetcher := NewArchivingFetcher(&DefaultFetcher{}, dir)
+	pageURL := server.URL + "/date/20250205.html"
+	html, err := fetcher.Fetch(pageURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := os.ReadFile(filepath.Join("..", "..", "testdata", "fx678", "20250205.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != string(original) {
+		t.Fatal("应原样返回被包装获取器的结果")
+	}
+
+	pages, err := ListArchive(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].URL != pageURL || !strings.HasSuffix(pages[0].Path, "-20250205.html.gz") {
+		t.Fatalf("归档页面: %+v", pages)
+	}
+	archived, err := pages[0].Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived != html {
+		t.Error("归档内容与下载的页面不一致")
+	}
+
+	// 获取失败时不归档
+	if _, err := fetcher.Fetch("http://127.0.0.1:0/date/20250205.html"); err == nil {
+		t.Error("无法连接时应返回错误")
+	}
+	if pages, _ := ListArchive(dir); len(pages) != 1 {
+		t.Errorf("获取失败不应写入归档: %+v", pages)
+	}
+}
+
+func TestReplayer(t *testing.T) {
+	dir := t.TempDir()
+	first := time.Date(2025, 2, 5, 21, 29, 58, 0, time.Local)
+	last := first.Add(time.Hour)
+	for _, page := range []struct {
+		html string
+		at   time.Time
+	}{
+		{"<html>公布前</html>", first},
+		{"<html>公布后</html>", last},
+	} {
+		if err := WriteArchive(dir, calendarURL, page.html, page.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewReplayer(dir, 0); err == nil {
+		t.Error("倍速为0时应返回错误")
+	}
+	if _, err := NewReplayer(t.TempDir(), 1); err == nil {
+		t.Error("空的归档目录应返回错误")
+	}
+
+	replayer, err := NewReplayer(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 虚拟时钟从第一个快照开始
+	html, err := replayer.Fetch(calendarURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "<html>公布前</html>" || replayer.Done() {
+		t.Errorf("回放开始时应返回第一个快照: %q, done=%v", html, replayer.Done())
+	}
+	if _, err := replayer.Fetch(importantURL); err == nil {
+		t.Error("归档中没有的URL应返回错误")
+	}
+	if _, err := replayer.Post(calendarURL, nil); err == nil {
+		t.Error("回放模式不支持POST请求")
+	}
+
+	// 虚拟时间越过最后一个快照
+	replayer.started = time.Now().Add(-2 * time.Hour)
+	if now := replayer.Now(); now.Before(first.Add(2 * time.Hour)) {
+		t.Errorf("虚拟时间 = %s", now)
+	}
+	html, err = replayer.Fetch(calendarURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if html != "<html>公布后</html>" || !replayer.Done() {
+		t.Errorf("回放结束后应返回最后一个快照: %q, done=%v", html, replayer.Done())
+	}
+}
@@ -1,10 +1,14 @@
 package htmlfetcher
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 // Fetcher 定义了获取HTML内容的接口
@@ -71,6 +75,84 @@ func (f *DefaultFetcher) Post(url string, data url.Values) (string, error) {
 	return string(body), nil
 }
 
+// JSONPoster 发送JSON请求
+type JSONPoster interface {
+	PostJSON(url string, body []byte) (string, error)
+}
+
+// StatusError 表示服务器返回了非 2xx 状态码
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s 返回状态码 %d: %s", e.URL, e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Temporary 判断错误是否可以重试：服务器错误或请求过多
+func (e *StatusError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// JSON请求的超时时间
+const postJSONTimeout = 30 * time.Second
+
+// PostJSON 发送JSON格式的POST请求，非 2xx 响应返回 *StatusError
+func (f *DefaultFetcher) PostJSON(url string, body []byte) (string, error) {
+	client := &http.Client{Timeout: postJSONTimeout}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	// 设置通用请求头
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	req.Header.Set("Accept", "application/json, */*;q=0.8")
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", &StatusError{URL: url, StatusCode: resp.StatusCode, Body: string(data)}
+	}
+
+	return string(data), nil
+}
+
+// FetchAll 并发获取多个URL，结果与 urls 顺序一致；
+// 任一请求失败时返回排在最前的错误
+func FetchAll(f Fetcher, urls []string) ([]string, error) {
+	pages := make([]string, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			pages[i], errs[i] = f.Fetch(u)
+		}(i, u)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return pages, nil
+}
+
 // NewFetcher 返回一个默认的HTML获取器实例
 func NewFetcher() Fetcher {
 	return &DefaultFetcher{}
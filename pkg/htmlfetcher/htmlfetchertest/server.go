@@ -0,0 +1,54 @@
+// Package htmlfetchertest 提供用于测试的本地日历页面替身服务器
+package htmlfetchertest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// Server 按 fx678 的URL结构（/date/YYYYMMDD.html）从本地目录提供页面
+type Server struct {
+	*httptest.Server
+
+	dir      string
+	mu       sync.Mutex
+	requests []string
+}
+
+// NewServer 启动一个从 dir 提供页面的服务器，调用方负责 Close
+func NewServer(dir string) *Server {
+	s := &Server{dir: dir}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.URL.Path)
+	s.mu.Unlock()
+
+	dir, name := path.Split(r.URL.Path)
+	if r.Method != http.MethodGet || dir != "/date/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(data)
+}
+
+// Requests 返回服务器收到的全部请求路径
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
@@ -0,0 +1,287 @@
+// Package ical 将财经日历事件和重要事件导出为 iCalendar (.ics)，
+// 可导入或订阅到常用的日历应用
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// Calendar 是一个日历，事件时间以数据源时区 Location 表示并附带对应的 VTIMEZONE
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Stamp    time.Time // DTSTAMP，零值时为当前时间
+
+	entries []entry
+	seen    map[string]int // 同一天相同事件出现的次数，用于区分 UID
+}
+
+// 一个 VEVENT
+type entry struct {
+	uid         string
+	date        string    // 所属日期 YYYY-MM-DD，时间未定时作为全天事件
+	at          time.Time // 开始时间，零值表示时间未定
+	summary     string
+	description []string
+	location    string
+	categories  []string
+}
+
+// New 返回名为 name 的空日历，loc 为数据源时区
+func New(name string, loc *time.Location) *Calendar {
+	return &Calendar{Name: name, Location: loc, seen: make(map[string]int)}
+}
+
+// AddEvents 添加 date（YYYY-MM-DD）当天的财经日历事件
+func (c *Calendar) AddEvents(date string, events []parser.CalendarEvent) {
+	for _, e := range events {
+		var description []string
+		for _, field := range [][2]string{
+			{"前值", e.Previous}, {"预测", e.Forecast}, {"公布值", e.Actual}, {"利多利空", e.Impact},
+		} {
+			if field[1] != "" {
+				description = append(description, field[0]+": "+field[1])
+			}
+		}
+		c.entries = append(c.entries, entry{
+			uid:         c.uid("event", date, e.Region, e.Indicator),
+			date:        date,
+			at:          e.At,
+			summary:     strings.TrimSpace(e.Region + " " + e.Indicator),
+			description: description,
+			categories:  categories("财经日历", e.Importance),
+		})
+	}
+}
+
+// AddImportantEvents 添加 date（YYYY-MM-DD）当天的重要事件
+func (c *Calendar) AddImportantEvents(date string, events []parser.ImportantEvent) {
+	for _, e := range events {
+		c.entries = append(c.entries, entry{
+			uid:        c.uid("important", date, e.Region, e.Event),
+			date:       date,
+			at:         e.At,
+			summary:    strings.TrimSpace(e.Region + " " + e.Event),
+			location:   e.Location,
+			categories: categories("重要事件", e.Importance),
+		})
+	}
+}
+
+func categories(kind, importance string) []string {
+	if importance == "" {
+		return []string{kind}
+	}
+	return []string{kind, importance}
+}
+
+// 由日期、地区和名称生成 UID，不含时间，因此刷新后时间确定或调整时
+// 日历应用会更新原事件而不是新增一个
+func (c *Calendar) uid(kind, date, region, name string) string {
+	key := strings.Join([]string{kind, date, region, name}, "|")
+	c.seen[key]++
+	if n := c.seen[key]; n > 1 {
+		key += fmt.Sprintf("|%d", n)
+	}
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:10]) + "@fmcl"
+}
+
+// Len 返回日历中的事件数
+func (c *Calendar) Len() int {
+	return len(c.entries)
+}
+
+// WriteTo 以 iCalendar 格式输出日历
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	stamp := c.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	tzid := c.Location.String()
+
+	out := &writer{w: w}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:-//fmcl//财经日历//ZH")
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	if c.Name != "" {
+		out.line("X-WR-CALNAME:" + escape(c.Name))
+	}
+	out.line("X-WR-TIMEZONE:" + tzid)
+	c.writeTimezone(out)
+
+	for _, e := range c.entries {
+		out.line("BEGIN:VEVENT")
+		out.line("UID:" + e.uid)
+		out.line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		if e.at.IsZero() {
+			day, _ := time.Parse("2006-01-02", e.date)
+			out.line("DTSTART;VALUE=DATE:" + day.Format("20060102"))
+		} else {
+			out.line("DTSTART;TZID=" + tzid + ":" + e.at.In(c.Location).Format("20060102T150405"))
+		}
+		out.line("SUMMARY:" + escape(e.summary))
+		if len(e.description) > 0 {
+			out.line("DESCRIPTION:" + escape(strings.Join(e.description, "\n")))
+		}
+		if e.location != "" {
+			out.line("LOCATION:" + escape(e.location))
+		}
+		escaped := make([]string, len(e.categories))
+		for i, category := range e.categories {
+			escaped[i] = escape(category)
+		}
+		out.line("CATEGORIES:" + strings.Join(escaped, ","))
+		out.line("TRANSP:TRANSPARENT")
+		out.line("END:VEVENT")
+	}
+	out.line("END:VCALENDAR")
+	return out.n, out.err
+}
+
+// 输出 VTIMEZONE，包含事件所在年份内的全部时区转换，没有转换时只有一个 STANDARD
+func (c *Calendar) writeTimezone(out *writer) {
+	from, to := c.span()
+	out.line("BEGIN:VTIMEZONE")
+	out.line("TZID:" + c.Location.String())
+
+	transitions := zoneTransitions(c.Location, from, to)
+	if len(transitions) == 0 {
+		name, offset := from.In(c.Location).Zone()
+		out.line("BEGIN:STANDARD")
+		out.line("DTSTART:19700101T000000")
+		out.line("TZOFFSETFROM:" + formatOffset(offset))
+		out.line("TZOFFSETTO:" + formatOffset(offset))
+		out.line("TZNAME:" + name)
+		out.line("END:STANDARD")
+	}
+	for _, t := range transitions {
+		component := "STANDARD"
+		if t.dst {
+			component = "DAYLIGHT"
+		}
+		out.line("BEGIN:" + component)
+		// DTSTART 为转换前的本地时间
+		out.line("DTSTART:" + t.at.In(time.FixedZone("", t.from)).Format("20060102T150405"))
+		out.line("TZOFFSETFROM:" + formatOffset(t.from))
+		out.line("TZOFFSETTO:" + formatOffset(t.to))
+		out.line("TZNAME:" + t.name)
+		out.line("END:" + component)
+	}
+	out.line("END:VTIMEZONE")
+}
+
+// 事件所在年份的范围，没有事件时为今年
+func (c *Calendar) span() (time.Time, time.Time) {
+	var first, last time.Time
+	for _, e := range c.entries {
+		day, err := time.ParseInLocation("2006-01-02", e.date, c.Location)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+	if first.IsZero() {
+		first = time.Now().In(c.Location)
+		last = first
+	}
+	return time.Date(first.Year(), 1, 1, 0, 0, 0, 0, c.Location),
+		time.Date(last.Year()+1, 1, 1, 0, 0, 0, 0, c.Location)
+}
+
+// 时区转换
+type transition struct {
+	at       time.Time
+	from, to int // 转换前后的UTC偏移（秒）
+	name     string
+	dst      bool
+}
+
+// 逐日查找 [from, to) 内 loc 的UTC偏移变化，再二分到秒
+func zoneTransitions(loc *time.Location, from, to time.Time) []transition {
+	var transitions []transition
+	_, offset := from.In(loc).Zone()
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		_, nextOffset := next.In(loc).Zone()
+		if nextOffset == offset {
+			continue
+		}
+		lo, hi := day, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2)
+			if _, o := mid.In(loc).Zone(); o == offset {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		name, _ := hi.In(loc).Zone()
+		transitions = append(transitions, transition{at: hi, from: offset, to: nextOffset, name: name, dst: hi.In(loc).IsDST()})
+		offset = nextOffset
+	}
+	return transitions
+}
+
+// 格式化UTC偏移，例如 +0800
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds%3600/60)
+}
+
+// 转义 TEXT 类型的值
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
+
+// 按 RFC 5545 以 CRLF 结尾并在75字节处折行的输出，不拆开UTF-8字符
+type writer struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (w *writer) line(content string) {
+	if w.err != nil {
+		return
+	}
+	var b strings.Builder
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		b.WriteString(content[:cut])
+		b.WriteString("\r\n ")
+		content = content[cut:]
+		limit = 74 // 续行开头的空格占一个字节
+	}
+	b.WriteString(content)
+	b.WriteString("\r\n")
+
+	n, err := io.WriteString(w.w, b.String())
+	w.n += int64(n)
+	w.err = err
+}
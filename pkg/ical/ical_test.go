@@ -0,0 +1,132 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+var stamp = time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC)
+
+func write(t *testing.T, c *Calendar) string {
+	t.Helper()
+	var b strings.Builder
+	if _, err := c.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+// 展开折行并按行拆分
+func unfold(text string) []string {
+	return strings.Split(strings.ReplaceAll(text, "\r\n ", ""), "\r\n")
+}
+
+func contains(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWriteTo(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	c := New("财经日历", loc)
+	c.Stamp = stamp
+	c.AddEvents("2025-02-05", []parser.CalendarEvent{
+		{Time: "21:15", Region: "美国", Indicator: "1月ADP就业人数(万)", Previous: "12.2", Forecast: "15", Importance: "高",
+			At: time.Date(2025, 2, 5, 21, 15, 0, 0, loc).UTC()},
+		{Time: "待定", Region: "欧元区", Indicator: "2月Sentix投资者信心指数", Previous: "-17.7", Importance: "中"},
+	})
+	c.AddImportantEvents("2025-02-05", []parser.ImportantEvent{
+		{Time: "17:00", Region: "欧盟", Location: "布鲁塞尔, 比利时", Importance: "高", Event: "欧盟财长会议",
+			At: time.Date(2025, 2, 5, 17, 0, 0, 0, loc)},
+	})
+
+	text := write(t, c)
+	if !strings.HasSuffix(text, "END:VCALENDAR\r\n") || strings.Contains(strings.ReplaceAll(text, "\r\n", ""), "\n") {
+		t.Error("每行应以 CRLF 结尾")
+	}
+	for _, line := range strings.Split(text, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("行超过75字节: %q", line)
+		}
+	}
+
+	lines := unfold(text)
+	for _, want := range []string{
+		"TZID:Asia/Shanghai",
+		"TZOFFSETTO:+0800",
+		"DTSTAMP:20250205T120000Z",
+		"DTSTART;TZID=Asia/Shanghai:20250205T211500",
+		"SUMMARY:美国 1月ADP就业人数(万)",
+		`DESCRIPTION:前值: 12.2\n预测: 15`,
+		"CATEGORIES:财经日历,高",
+		"DTSTART;VALUE=DATE:20250205",
+		"DTSTART;TZID=Asia/Shanghai:20250205T170000",
+		`LOCATION:布鲁塞尔\, 比利时`,
+		"CATEGORIES:重要事件,高",
+	} {
+		if !contains(lines, want) {
+			t.Errorf("缺少 %q\n%s", want, text)
+		}
+	}
+	if c.Len() != 3 {
+		t.Errorf("事件数 = %d", c.Len())
+	}
+}
+
+func TestStableUID(t *testing.T) {
+	uids := func(events []parser.CalendarEvent) []string {
+		c := New("", time.UTC)
+		c.AddEvents("2025-02-05", events)
+		var uids []string
+		for _, line := range unfold(write(t, c)) {
+			if strings.HasPrefix(line, "UID:") {
+				uids = append(uids, line)
+			}
+		}
+		return uids
+	}
+
+	before := uids([]parser.CalendarEvent{
+		{Time: "待定", Region: "美国", Indicator: "美联储官员讲话"},
+		{Time: "待定", Region: "美国", Indicator: "美联储官员讲话"},
+	})
+	// 刷新后时间确定、数值公布，UID 不变
+	after := uids([]parser.CalendarEvent{
+		{Time: "01:00", Region: "美国", Indicator: "美联储官员讲话", Actual: "-", At: stamp},
+		{Time: "03:00", Region: "美国", Indicator: "美联储官员讲话", At: stamp.Add(2 * time.Hour)},
+	})
+	if len(before) != 2 || before[0] == before[1] {
+		t.Errorf("同一天的相同事件应有不同的 UID: %v", before)
+	}
+	if strings.Join(before, " ") != strings.Join(after, " ") {
+		t.Errorf("刷新后 UID 变化: %v -> %v", before, after)
+	}
+}
+
+func TestTimezoneTransitions(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	c := New("", loc)
+	c.AddEvents("2025-02-05", []parser.CalendarEvent{{Region: "英国", Indicator: "1月Halifax房价指数月率"}})
+	lines := unfold(write(t, c))
+	for _, want := range []string{
+		"BEGIN:DAYLIGHT", "DTSTART:20250330T010000", "TZOFFSETFROM:+0000", "TZOFFSETTO:+0100", "TZNAME:BST",
+		"BEGIN:STANDARD", "DTSTART:20251026T020000", "TZNAME:GMT",
+	} {
+		if !contains(lines, want) {
+			t.Errorf("缺少 %q", want)
+		}
+	}
+}
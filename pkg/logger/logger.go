@@ -10,6 +10,15 @@ import (
 var Log *zap.Logger
 
 func NewLogger(logPath string) (*zap.Logger, error) {
+	return build(logPath, []string{logPath, "stdout"}, []string{logPath, "stderr"})
+}
+
+// NewFileLogger 创建只写入日志文件的日志，用于把结果输出到标准输出的命令
+func NewFileLogger(logPath string) (*zap.Logger, error) {
+	return build(logPath, []string{logPath}, []string{logPath})
+}
+
+func build(logPath string, outputPaths, errorOutputPaths []string) (*zap.Logger, error) {
 	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
 		return nil, err
 	}
@@ -34,8 +43,8 @@ func NewLogger(logPath string) (*zap.Logger, error) {
 		Sampling:          nil,
 		Encoding:          "json",
 		EncoderConfig:    encoderConfig,
-		OutputPaths:      []string{logPath, "stdout"},
-		ErrorOutputPaths: []string{logPath, "stderr"},
+		OutputPaths:      outputPaths,
+		ErrorOutputPaths: errorOutputPaths,
 	}
 
 	logger, err := config.Build(zap.AddCaller())
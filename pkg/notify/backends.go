@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/logger"
+)
+
+// Console 在终端响铃并把提醒写入日志
+type Console struct {
+	Writer io.Writer
+}
+
+// NewConsole 返回向标准输出响铃的 Console
+func NewConsole() *Console {
+	return &Console{Writer: os.Stdout}
+}
+
+// Name 返回渠道名称
+func (c *Console) Name() string {
+	return "console"
+}
+
+// Notify 输出响铃字符，不输出文本以免破坏终端界面
+func (c *Console) Notify(alert Alert) error {
+	logger.Info("提醒", zap.String("title", alert.Title()), zap.String("body", alert.Body()))
+	_, err := io.WriteString(c.Writer, "\a")
+	return err
+}
+
+// 默认的桌面通知命令
+const DefaultDesktopCommand = "notify-send"
+
+// Desktop 调用 notify-send 风格的命令发送桌面通知，
+// 命令参数为 Args 之后依次追加标题和正文
+type Desktop struct {
+	Command string
+	Args    []string
+}
+
+// NewDesktop 返回使用 command 的桌面通知，command 为空时使用 notify-send
+func NewDesktop(command string, args ...string) *Desktop {
+	if command == "" {
+		command = DefaultDesktopCommand
+	}
+	return &Desktop{Command: command, Args: args}
+}
+
+// Name 返回渠道名称
+func (d *Desktop) Name() string {
+	return "desktop"
+}
+
+// Notify 执行通知命令
+func (d *Desktop) Notify(alert Alert) error {
+	args := append(append([]string(nil), d.Args...), alert.Title(), alert.Body())
+	out, err := exec.Command(d.Command, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("执行 %s 失败: %v %s", d.Command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Email 通过 SMTP 发送邮件提醒，Username 为空时不认证
+type Email struct {
+	Addr     string // host:port
+	From     string
+	To       []string
+	Username string
+	Password string
+}
+
+// Name 返回渠道名称
+func (m *Email) Name() string {
+	return "email"
+}
+
+// Notify 发送一封纯文本邮件
+func (m *Email) Notify(alert Alert) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		host, _, err := net.SplitHostPort(m.Addr)
+		if err != nil {
+			return fmt.Errorf("无效的SMTP地址 %q: %v", m.Addr, err)
+		}
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", alert.Title()))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(alert.Body())
+	msg.WriteString("\r\n")
+
+	return smtp.SendMail(m.Addr, auth, m.From, m.To, []byte(msg.String()))
+}
@@ -0,0 +1,133 @@
+// Package notify 在高重要性财经数据公布前和公布后发送提醒
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// Kind 表示提醒的类型
+type Kind string
+
+const (
+	KindUpcoming Kind = "upcoming" // 即将公布
+	KindReleased Kind = "released" // 已公布
+	KindRule     Kind = "rule"     // 命中提醒规则
+)
+
+// Alert 表示一条提醒
+type Alert struct {
+	Kind  Kind
+	Date  time.Time // 事件所属日期（数据源时区）
+	Event parser.CalendarEvent
+	Rule  string // 命中的规则名称，仅 KindRule
+}
+
+// Title 返回提醒标题
+func (a Alert) Title() string {
+	prefix := "即将公布"
+	switch a.Kind {
+	case KindReleased:
+		prefix = "已公布"
+	case KindRule:
+		prefix = "规则 " + a.Rule
+	}
+	return fmt.Sprintf("%s: %s %s", prefix, a.Event.Region, a.Event.Indicator)
+}
+
+// Body 返回提醒正文
+func (a Alert) Body() string {
+	e := a.Event
+	parts := []string{fmt.Sprintf("%s %s", a.Date.Format("2006-01-02"), e.Time)}
+	released := e.Actual != "" && a.Kind != KindUpcoming
+	if released {
+		parts = append(parts, "公布值 "+e.Actual)
+	}
+	if e.Previous != "" || e.Forecast != "" {
+		parts = append(parts, "前值 "+e.Previous, "预测 "+e.Forecast)
+	}
+	if released && e.Impact != "" {
+		parts = append(parts, e.Impact)
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Backend 是提醒的发送渠道
+type Backend interface {
+	Name() string
+	Notify(alert Alert) error
+}
+
+// Dispatcher 将提醒发送到全部渠道
+type Dispatcher struct {
+	Backends []Backend
+}
+
+// Send 依次发送提醒，单个渠道失败不影响其它渠道，返回全部错误
+func (d *Dispatcher) Send(alerts ...Alert) error {
+	var errs []error
+	for _, alert := range alerts {
+		for _, b := range d.Backends {
+			if err := b.Notify(alert); err != nil {
+				logger.Error("发送提醒失败",
+					zap.String("backend", b.Name()),
+					zap.String("indicator", alert.Event.Indicator),
+					zap.Error(err))
+				errs = append(errs, fmt.Errorf("%s: %v", b.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New 按 notification_methods 创建提醒渠道
+func New(cfg *config.AppConfig) (*Dispatcher, error) {
+	n := cfg.Notification
+	d := &Dispatcher{}
+	for _, method := range cfg.NotificationMethods {
+		switch method {
+		case "console":
+			d.Backends = append(d.Backends, NewConsole())
+		case "desktop":
+			d.Backends = append(d.Backends, NewDesktop(n.Desktop.Command, n.Desktop.Args...))
+		case "webhook":
+			if len(n.Webhooks) == 0 {
+				return nil, fmt.Errorf("webhook 提醒缺少 notification.webhooks")
+			}
+			path := n.DeadLetter
+			if path == "" {
+				path = DefaultDeadLetterPath
+			}
+			deadLetter := &DeadLetter{Path: path}
+			for _, wc := range n.Webhooks {
+				w, err := NewWebhook(wc, deadLetter)
+				if err != nil {
+					return nil, err
+				}
+				d.Backends = append(d.Backends, w)
+			}
+		case "email":
+			if n.Email.Addr == "" || n.Email.From == "" || len(n.Email.To) == 0 {
+				return nil, fmt.Errorf("email 提醒需要 notification.email 的 addr、from 和 to")
+			}
+			d.Backends = append(d.Backends, &Email{
+				Addr:     n.Email.Addr,
+				From:     n.Email.From,
+				To:       n.Email.To,
+				Username: n.Email.Username,
+				Password: n.Email.Password,
+			})
+		default:
+			return nil, fmt.Errorf("未知的提醒方式: %s", method)
+		}
+	}
+	return d, nil
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+var testDay = time.Date(2025, 2, 5, 0, 0, 0, 0, time.UTC)
+
+func testEvent() parser.CalendarEvent {
+	return parser.CalendarEvent{
+		Time:       "21:15",
+		Region:     "美国",
+		Indicator:  "1月ADP就业人数(万)",
+		Previous:   "12.2",
+		Forecast:   "15.0",
+		Importance: "高",
+		At:         time.Date(2025, 2, 5, 21, 15, 0, 0, time.UTC),
+	}
+}
+
+func TestWatcherUpcomingAndReleased(t *testing.T) {
+	w := NewWatcher(5 * time.Minute)
+	e := testEvent()
+	low := e
+	low.Indicator, low.Importance = "12月贸易帐(亿美元)", "低"
+	events := []parser.CalendarEvent{e, low}
+
+	if alerts := w.Check(testDay, events, e.At.Add(-10*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("提前10分钟不应提醒: %+v", alerts)
+	}
+	alerts := w.Check(testDay, events, e.At.Add(-4*time.Minute))
+	if len(alerts) != 1 || alerts[0].Kind != KindUpcoming || alerts[0].Event.Indicator != e.Indicator {
+		t.Fatalf("提前4分钟应提醒一次: %+v", alerts)
+	}
+	if alerts := w.Check(testDay, events, e.At.Add(-3*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("同一事件不应重复提醒: %+v", alerts)
+	}
+
+	e.Actual = "18.3"
+	low.Actual = "-980"
+	alerts = w.Check(testDay, []parser.CalendarEvent{e, low}, e.At.Add(time.Minute))
+	if len(alerts) != 1 || alerts[0].Kind != KindReleased {
+		t.Fatalf("公布值出现时应提醒一次: %+v", alerts)
+	}
+	if !strings.Contains(alerts[0].Body(), "公布值 18.3") {
+		t.Errorf("公布提醒正文 = %q", alerts[0].Body())
+	}
+	if alerts := w.Check(testDay, []parser.CalendarEvent{e}, e.At.Add(2*time.Minute)); len(alerts) != 0 {
+		t.Fatalf("公布提醒不应重复: %+v", alerts)
+	}
+}
+
+func TestWatcherIgnoresAlreadyReleased(t *testing.T) {
+	w := NewWatcher(5 * time.Minute)
+	e := testEvent()
+	e.Actual = "18.3"
+	if alerts := w.Check(testDay, []parser.CalendarEvent{e}, e.At.Add(time.Hour)); len(alerts) != 0 {
+		t.Fatalf("启动时已公布的事件不应提醒: %+v", alerts)
+	}
+}
+
+func TestWatcherPrune(t *testing.T) {
+	w := NewWatcher(5 * time.Minute)
+	e := testEvent()
+	w.Check(testDay, []parser.CalendarEvent{e}, e.At.Add(-4*time.Minute))
+	tomorrow := testDay.AddDate(0, 0, 1)
+	next := e
+	next.At = e.At.AddDate(0, 0, 1)
+	w.Check(tomorrow, []parser.CalendarEvent{next}, next.At.Add(-4*time.Minute))
+
+	w.Prune(tomorrow)
+	if len(w.sent) != 1 || len(w.pending) != 1 {
+		t.Fatalf("应只保留今天的记录: sent %v pending %v", w.sent, w.pending)
+	}
+	if alerts := w.Check(tomorrow, []parser.CalendarEvent{next}, next.At.Add(-3*time.Minute)); len(alerts) != 0 {
+		t.Errorf("今天的记录不应被清除: %+v", alerts)
+	}
+}
+
+func TestConsoleRingsBell(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&Console{Writer: &buf}).Notify(Alert{Kind: KindUpcoming, Date: testDay, Event: testEvent()}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "\a" {
+		t.Errorf("输出 = %q, 期望响铃字符", buf.String())
+	}
+}
+
+func TestDesktopRunsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 sh")
+	}
+	dir := t.TempDir()
+	out := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "notify-send")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+out+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	alert := Alert{Kind: KindUpcoming, Date: testDay, Event: testEvent()}
+	if err := NewDesktop(script, "-u", "critical").Notify(alert); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{"-u", "critical", alert.Title(), alert.Body()}, "\n") + "\n"
+	if string(data) != want {
+		t.Errorf("命令参数 = %q, 期望 %q", data, want)
+	}
+}
+
+// 启动一个只接收一封邮件的本地SMTP替身，返回地址和收到的邮件内容
+func startSMTPServer(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		var data strings.Builder
+		inData := false
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if inData {
+				if line == ".\r\n" {
+					inData = false
+					received <- data.String()
+					reply("250 OK")
+					continue
+				}
+				data.WriteString(line)
+				continue
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO", "HELO", "MAIL", "RCPT", "RSET", "NOOP":
+				reply("250 OK")
+			case "DATA":
+				inData = true
+				reply("354 End data with <CR><LF>.<CR><LF>")
+			case "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("502 Unsupported")
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestEmailSendsMail(t *testing.T) {
+	addr, received := startSMTPServer(t)
+	e := testEvent()
+	e.Actual = "18.3"
+
+	m := &Email{Addr: addr, From: "fmcl@example.com", To: []string{"trader@example.com"}}
+	if err := m.Notify(Alert{Kind: KindReleased, Date: testDay, Event: e}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-received:
+		for _, want := range []string{"To: trader@example.com", "Subject: =?UTF-8?b?", "公布值 18.3"} {
+			if !strings.Contains(msg, want) {
+				t.Errorf("邮件缺少 %q:\n%s", want, msg)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SMTP替身没有收到邮件")
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	cfg := &config.AppConfig{NotificationMethods: []string{"console", "desktop"}}
+	d, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Backends) != 2 || d.Backends[1].(*Desktop).Command != DefaultDesktopCommand {
+		t.Errorf("渠道 = %+v", d.Backends)
+	}
+
+	for _, methods := range [][]string{{"webhook"}, {"email"}, {"pager"}} {
+		if _, err := New(&config.AppConfig{NotificationMethods: methods}); err == nil {
+			t.Errorf("%v 缺少设置或未知时应返回错误", methods)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// 默认提前提醒的时间
+const DefaultLead = 5 * time.Minute
+
+// Watcher 跟踪高重要性事件的状态，在公布前 Lead 时间和公布值出现时各产生一次提醒
+type Watcher struct {
+	Lead time.Duration
+
+	sent    map[string]time.Time // 已发送的提醒及事件日期
+	pending map[string]time.Time // 见过且尚未公布的事件及其日期
+}
+
+// NewWatcher 返回提前 lead 时间提醒的 Watcher
+func NewWatcher(lead time.Duration) *Watcher {
+	return &Watcher{
+		Lead:    lead,
+		sent:    make(map[string]time.Time),
+		pending: make(map[string]time.Time),
+	}
+}
+
+// 事件的索引键
+func watchKey(day time.Time, e parser.CalendarEvent) string {
+	return day.Format("2006-01-02") + "|" + e.Time + "|" + e.Region + "|" + e.Indicator
+}
+
+// Check 检查某一天的事件，返回本次需要发送的提醒。
+// 只有先前见过未公布状态的事件才会产生公布提醒，启动时已公布的事件不会重复提醒
+func (w *Watcher) Check(day time.Time, events []parser.CalendarEvent, now time.Time) []Alert {
+	var alerts []Alert
+	for _, e := range events {
+		if e.Importance != "高" {
+			continue
+		}
+		key := watchKey(day, e)
+
+		if e.Actual != "" {
+			if _, ok := w.pending[key]; ok {
+				delete(w.pending, key)
+				alerts = append(alerts, Alert{Kind: KindReleased, Date: day, Event: e})
+			}
+			continue
+		}
+		w.pending[key] = day
+
+		if _, ok := w.sent[key]; ok || e.At.IsZero() {
+			continue
+		}
+		if !now.Before(e.At.Add(-w.Lead)) && now.Before(e.At) {
+			w.sent[key] = day
+			alerts = append(alerts, Alert{Kind: KindUpcoming, Date: day, Event: e})
+		}
+	}
+	return alerts
+}
+
+// Prune 清除 today 之前的事件记录，避免长时间运行时无限增长
+func (w *Watcher) Prune(today time.Time) {
+	for _, m := range []map[string]time.Time{w.sent, w.pending} {
+		for key, day := range m {
+			if day.Before(today) {
+				delete(m, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"text/template"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// webhook 默认的重试次数和首次重试间隔
+const (
+	DefaultWebhookRetries = 3
+	DefaultWebhookBackoff = time.Second
+)
+
+// DefaultDeadLetterPath 默认的死信文件
+var DefaultDeadLetterPath = filepath.Join("logs", "webhook_dead_letter.jsonl")
+
+// Payload 是 webhook 模板的数据
+type Payload struct {
+	Kind  Kind
+	Title string
+	Body  string
+	Date  string // YYYY-MM-DD
+	Event parser.CalendarEvent
+	Rule  string // 命中的规则名称，仅 rule 类型
+}
+
+// 模板中可用的函数
+var templateFuncs = template.FuncMap{
+	// json 将值编码为JSON，用于在模板中安全地嵌入字符串
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// 默认的请求体
+const defaultWebhookTemplate = `{"kind":{{json .Kind}},"title":{{json .Title}},"body":{{json .Body}},"date":{{json .Date}},` +
+	`"time":{{json .Event.Time}},"region":{{json .Event.Region}},"indicator":{{json .Event.Indicator}},` +
+	`"previous":{{json .Event.Previous}},"forecast":{{json .Event.Forecast}},"actual":{{json .Event.Actual}},` +
+	`"importance":{{json .Event.Importance}},"impact":{{json .Event.Impact}}}`
+
+// Webhook 按模板生成JSON并POST到目标地址，失败时按指数退避重试，
+// 最终失败的请求写入死信文件
+type Webhook struct {
+	Destination string
+	URL         string
+	Template    *template.Template
+	Kinds       map[Kind]bool // 为空时发送全部类型
+	Retries     int
+	Backoff     time.Duration
+	Poster      htmlfetcher.JSONPoster
+	DeadLetter  *DeadLetter
+
+	sleep func(time.Duration)
+}
+
+// NewWebhook 按配置创建 webhook，模板无法解析时返回错误
+func NewWebhook(cfg config.WebhookConfig, deadLetter *DeadLetter) (*Webhook, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("webhook %q 缺少 url", cfg.Name)
+	}
+
+	text := cfg.Template
+	if text == "" {
+		text = defaultWebhookTemplate
+	}
+	tmpl, err := template.New(cfg.Name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("解析 webhook %q 的模板失败: %v", cfg.Name, err)
+	}
+
+	w := &Webhook{
+		Destination: cfg.Name,
+		URL:         cfg.URL,
+		Template:    tmpl,
+		Retries:     cfg.Retries,
+		Backoff:     cfg.Backoff,
+		Poster:      &htmlfetcher.DefaultFetcher{},
+		DeadLetter:  deadLetter,
+		sleep:       time.Sleep,
+	}
+	if w.Destination == "" {
+		w.Destination = cfg.URL
+	}
+	if w.Retries <= 0 {
+		w.Retries = DefaultWebhookRetries
+	}
+	if w.Backoff <= 0 {
+		w.Backoff = DefaultWebhookBackoff
+	}
+	for _, k := range cfg.Kinds {
+		kind := Kind(k)
+		if kind != KindUpcoming && kind != KindReleased && kind != KindRule {
+			return nil, fmt.Errorf("webhook %q 的提醒类型无效: %s", cfg.Name, k)
+		}
+		if w.Kinds == nil {
+			w.Kinds = make(map[Kind]bool)
+		}
+		w.Kinds[kind] = true
+	}
+	return w, nil
+}
+
+// Name 返回渠道名称
+func (w *Webhook) Name() string {
+	return "webhook:" + w.Destination
+}
+
+// Render 按模板生成请求体，结果必须是合法的JSON
+func (w *Webhook) Render(alert Alert) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.Template.Execute(&buf, Payload{
+		Kind:  alert.Kind,
+		Title: alert.Title(),
+		Body:  alert.Body(),
+		Date:  alert.Date.Format("2006-01-02"),
+		Event: alert.Event,
+		Rule:  alert.Rule,
+	}); err != nil {
+		return nil, fmt.Errorf("执行模板失败: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("模板生成的请求体不是合法的JSON: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// Notify 发送提醒，服务器错误和网络错误会重试
+func (w *Webhook) Notify(alert Alert) error {
+	if w.Kinds != nil && !w.Kinds[alert.Kind] {
+		return nil
+	}
+
+	body, err := w.Render(alert)
+	if err != nil {
+		w.deadLetter(alert, nil, 0, err)
+		return err
+	}
+
+	attempts := 0
+	backoff := w.Backoff
+	for {
+		attempts++
+		_, err = w.Poster.PostJSON(w.URL, body)
+		if err == nil {
+			return nil
+		}
+
+		var statusErr *htmlfetcher.StatusError
+		retryable := !errors.As(err, &statusErr) || statusErr.Temporary()
+		if !retryable || attempts > w.Retries {
+			break
+		}
+		logger.Warn("webhook 发送失败，稍后重试",
+			zap.String("destination", w.Destination),
+			zap.Int("attempt", attempts),
+			zap.Duration("backoff", backoff),
+			zap.Error(err))
+		w.sleep(backoff)
+		backoff *= 2
+	}
+
+	w.deadLetter(alert, body, attempts, err)
+	return fmt.Errorf("发送 %d 次后失败: %v", attempts, err)
+}
+
+// 记录最终失败的请求
+func (w *Webhook) deadLetter(alert Alert, body []byte, attempts int, cause error) {
+	if w.DeadLetter == nil {
+		return
+	}
+	entry := DeadLetterEntry{
+		Time:        time.Now(),
+		Destination: w.Destination,
+		URL:         w.URL,
+		Title:       alert.Title(),
+		Payload:     string(body),
+		Attempts:    attempts,
+		Error:       cause.Error(),
+	}
+	if err := w.DeadLetter.Write(entry); err != nil {
+		logger.Error("写入死信文件失败", zap.String("path", w.DeadLetter.Path), zap.Error(err))
+	}
+}
+
+// DeadLetterEntry 是死信文件中的一条记录
+type DeadLetterEntry struct {
+	Time        time.Time `json:"time"`
+	Destination string    `json:"destination"`
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Payload     string    `json:"payload"`
+	Attempts    int       `json:"attempts"`
+	Error       string    `json:"error"`
+}
+
+// DeadLetter 以 JSON Lines 格式追加记录最终发送失败的请求
+type DeadLetter struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Write 追加一条记录
+func (d *DeadLetter) Write(entry DeadLetterEntry) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(d.Path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(d.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return file.Close()
+}
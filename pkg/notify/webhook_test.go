@@ -0,0 +1,185 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/config"
+)
+
+// 记录请求并按顺序返回预设状态码的替身服务器
+type webhookServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+}
+
+func newWebhookServer(t *testing.T, statuses ...int) *webhookServer {
+	s := &webhookServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+			t.Errorf("Content-Type = %s", ct)
+		}
+		status := http.StatusOK
+		if i := len(s.bodies); i < len(s.statuses) {
+			status = s.statuses[i]
+		}
+		s.bodies = append(s.bodies, string(data))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *webhookServer) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+func newTestWebhook(t *testing.T, cfg config.WebhookConfig, deadLetter *DeadLetter) (*Webhook, *[]time.Duration) {
+	t.Helper()
+	w, err := NewWebhook(cfg, deadLetter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var waits []time.Duration
+	w.sleep = func(d time.Duration) { waits = append(waits, d) }
+	return w, &waits
+}
+
+func releasedAlert() Alert {
+	e := testEvent()
+	e.Actual = "18.3"
+	e.Impact = "利多 美元"
+	return Alert{Kind: KindReleased, Date: testDay, Event: e}
+}
+
+func TestWebhookDefaultPayload(t *testing.T) {
+	server := newWebhookServer(t)
+	w, _ := newTestWebhook(t, config.WebhookConfig{Name: "chat", URL: server.URL}, nil)
+
+	if err := w.Notify(releasedAlert()); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := json.Unmarshal([]byte(server.requests()[0]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["kind"] != "released" || got["indicator"] != "1月ADP就业人数(万)" || got["actual"] != "18.3" || got["date"] != "2025-02-05" {
+		t.Errorf("请求体 = %v", got)
+	}
+}
+
+func TestWebhookTemplate(t *testing.T) {
+	server := newWebhookServer(t)
+	w, _ := newTestWebhook(t, config.WebhookConfig{
+		Name:     "chat",
+		URL:      server.URL,
+		Template: `{"msg_type":"text","content":{"text":{{json (printf "%s %s 公布 %s" .Event.Region .Event.Indicator .Event.Actual)}}}}`,
+		Kinds:    []string{"released"},
+	}, nil)
+
+	upcoming := releasedAlert()
+	upcoming.Kind = KindUpcoming
+	if err := w.Notify(upcoming); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Notify(releasedAlert()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.requests()
+	if len(requests) != 1 {
+		t.Fatalf("只应发送已公布提醒，收到 %d 个请求", len(requests))
+	}
+	want := `{"msg_type":"text","content":{"text":"美国 1月ADP就业人数(万) 公布 18.3"}}`
+	if requests[0] != want {
+		t.Errorf("请求体 = %s, 期望 %s", requests[0], want)
+	}
+}
+
+func TestWebhookRetriesWithBackoff(t *testing.T) {
+	server := newWebhookServer(t, http.StatusBadGateway, http.StatusTooManyRequests)
+	w, waits := newTestWebhook(t, config.WebhookConfig{URL: server.URL, Backoff: 100 * time.Millisecond}, nil)
+
+	if err := w.Notify(releasedAlert()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(server.requests()); n != 3 {
+		t.Errorf("请求次数 = %d, 期望 3", n)
+	}
+	if want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}; len(*waits) != 2 || (*waits)[0] != want[0] || (*waits)[1] != want[1] {
+		t.Errorf("退避间隔 = %v, 期望 %v", *waits, want)
+	}
+}
+
+func TestWebhookDeadLetter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead.jsonl")
+	deadLetter := &DeadLetter{Path: path}
+
+	failing := newWebhookServer(t, 500, 500, 500)
+	w, _ := newTestWebhook(t, config.WebhookConfig{Name: "chat", URL: failing.URL, Retries: 2}, deadLetter)
+	if err := w.Notify(releasedAlert()); err == nil {
+		t.Fatal("持续失败时应返回错误")
+	}
+	if n := len(failing.requests()); n != 3 {
+		t.Errorf("请求次数 = %d, 期望 1+2次重试", n)
+	}
+
+	// 客户端错误不重试
+	rejected := newWebhookServer(t, http.StatusBadRequest)
+	w, _ = newTestWebhook(t, config.WebhookConfig{Name: "bad", URL: rejected.URL}, deadLetter)
+	if err := w.Notify(releasedAlert()); err == nil {
+		t.Fatal("400 应返回错误")
+	}
+	if n := len(rejected.requests()); n != 1 {
+		t.Errorf("400 不应重试，请求次数 = %d", n)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("死信记录数 = %d, 期望 2", len(lines))
+	}
+	var entry DeadLetterEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Destination != "chat" || entry.Attempts != 3 || !json.Valid([]byte(entry.Payload)) {
+		t.Errorf("死信记录 = %+v", entry)
+	}
+}
+
+func TestNewWebhookRejectsBadConfig(t *testing.T) {
+	for _, cfg := range []config.WebhookConfig{
+		{Name: "no-url"},
+		{Name: "bad-template", URL: "http://localhost", Template: "{{.Missing"},
+		{Name: "bad-kind", URL: "http://localhost", Kinds: []string{"later"}},
+	} {
+		if _, err := NewWebhook(cfg, nil); err == nil {
+			t.Errorf("%s 应返回错误", cfg.Name)
+		}
+	}
+
+	w, _ := newTestWebhook(t, config.WebhookConfig{URL: "http://localhost", Template: `{"text": {{.Title}}}`}, nil)
+	if _, err := w.Render(releasedAlert()); err == nil {
+		t.Error("生成非法JSON时应返回错误")
+	}
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
@@ -9,124 +11,236 @@ import (
 
 // CalendarEvent 表示一个财经日历事件
 type CalendarEvent struct {
-	Time        string  // 时间
-	Region      string  // 地区
-	Indicator   string  // 指标
-	Previous    string  // 前值
-	Forecast    string  // 预测值
-	Actual      string  // 公布值
-	Importance  string  // 重要性
-	Impact      string  // 利多利空
-	Description string  // 解读
+	Time        string `json:"time"`        // 时间
+	Region      string `json:"region"`      // 地区
+	Indicator   string `json:"indicator"`   // 指标
+	Previous    string `json:"previous"`    // 前值
+	Forecast    string `json:"forecast"`    // 预测值
+	Actual      string `json:"actual"`      // 公布值
+	Importance  string `json:"importance"`  // 重要性
+	Impact      string `json:"impact"`      // 利多利空
+	Description string `json:"description"` // 解读
+
+	At time.Time `json:"at"` // 完整的公布时间（数据源时区），时间未定时为零值
+
+	PreviousValue Value `json:"previous_value"` // 解析后的前值
+	ForecastValue Value `json:"forecast_value"` // 解析后的预测值
+	ActualValue   Value `json:"actual_value"`   // 解析后的公布值
 }
 
 // ImportantEvent 表示一个重要事件
 type ImportantEvent struct {
-	Time       string // 时间
-	Region     string // 国家地区
-	Location   string // 地点
-	Importance string // 重要性
-	Event      string // 事件内容
+	Time       string `json:"time"`       // 时间
+	Region     string `json:"region"`     // 国家地区
+	Location   string `json:"location"`   // 地点
+	Importance string `json:"importance"` // 重要性
+	Event      string `json:"event"`      // 事件内容
+
+	At time.Time `json:"at"` // 完整的事件时间（数据源时区），时间未定时为零值
 }
 
 // CentralBankRate 表示央行利率信息
 type CentralBankRate struct {
-	Bank           string    // 央行名称
-	RateName       string    // 利率名称
-	CurrentRate    string    // 当前值
-	PreviousRate   string    // 前次值
-	LastChange     string    // 最近非0变动基点
-	HistoryHigh    string    // 历史峰值
-	HistoryLow     string    // 历史最低
-	NextForecast   string    // 下次预测值
-	LatestCPI      string    // CPI最新值
-	LastUpdateTime time.Time // 最后更新时间
+	Bank           string    `json:"bank"`             // 央行名称
+	RateName       string    `json:"rate_name"`        // 利率名称
+	CurrentRate    string    `json:"current_rate"`     // 当前值
+	PreviousRate   string    `json:"previous_rate"`    // 前次值
+	LastChange     string    `json:"last_change"`      // 最近非0变动基点
+	HistoryHigh    string    `json:"history_high"`     // 历史峰值
+	HistoryLow     string    `json:"history_low"`      // 历史最低
+	NextForecast   string    `json:"next_forecast"`    // 下次预测值
+	LatestCPI      string    `json:"latest_cpi"`       // CPI最新值
+	LastUpdateTime time.Time `json:"last_update_time"` // 最后更新时间
+}
+
+// 指标名称开头的统计期，例如 "1月"、"第四季度"、"2月1日当周"
+var periodPrefix = regexp.MustCompile(`^\s*(\d{1,2}月\d{1,2}日当周|\d{1,2}/\d{1,2}当周|\d{1,2}月\d{1,2}日|\d{4}年\d{1,2}月|\d{1,2}月份?|第[一二三四1-4]季度|\d{4}年|当周)`)
+
+// SeriesName 返回去掉统计期前缀的指标名称，用于跨期比较同一指标
+func SeriesName(indicator string) string {
+	return strings.TrimSpace(periodPrefix.ReplaceAllString(indicator, ""))
+}
+
+// 重要性的英文写法
+var importanceLevels = map[string]string{
+	"high": "高", "medium": "中", "low": "低",
+	"高": "高", "中": "中", "低": "低",
+	"all": "", "": "",
+}
+
+// ParseImportance 将 high/medium/low 或 高/中/低 转换为页面上的重要性，
+// all 和空串返回空串表示不过滤
+func ParseImportance(name string) (string, error) {
+	level, ok := importanceLevels[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("未知的重要性 %q（可选 high、medium、low、all）", name)
+	}
+	return level, nil
+}
+
+// Result 表示一次页面解析的结果
+type Result struct {
+	Events          []CalendarEvent   `json:"events"`
+	ImportantEvents []ImportantEvent  `json:"important_events"`
+	Rates           []CentralBankRate `json:"rates"`
+	Warnings        []Warning         `json:"warnings"`
 }
 
 // ParseFinancialCalendar 解析财经日历页面
 func ParseFinancialCalendar(html string) ([]CalendarEvent, []ImportantEvent, []CentralBankRate, error) {
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	result, err := Parse(html)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	return result.Events, result.ImportantEvents, result.Rates, nil
+}
+
+// Parse 解析财经日历页面。各表格按表头文本映射列，
+// 同时校验页面结构并返回警告
+func Parse(html string) (*Result, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return nil, err
+	}
 
-	var events []CalendarEvent
-	var importantEvents []ImportantEvent
-	var rates []CentralBankRate
+	result := &Result{}
 
 	// 解析财经日历事件
-	doc.Find("table.cjsj_tab tr").Each(func(i int, tr *goquery.Selection) {
+	calendarTable := doc.Find("table.cjsj_tab").First()
+	if calendarTable.Length() == 0 {
+		result.Warnings = append(result.Warnings, Warning{Kind: WarningMissingTable, Table: TableCalendar, Detail: "找不到 table.cjsj_tab"})
+	} else {
+		parseCalendarTable(calendarTable, result)
+	}
+
+	// 按表头识别重要事件表和央行利率表
+	foundRates := false
+	doc.Find("table.cjsj_tab2").Each(func(i int, table *goquery.Selection) {
+		if columns, _, _ := mapColumns(TableRates, table, rateHeaders); columns.has(rateRequired...) {
+			foundRates = true
+			parseRateTable(table, result)
+			return
+		}
+		if columns, _, _ := mapColumns(TableImportant, table, importantHeaders); columns.has(importantRequired...) {
+			parseImportantTable(table, result)
+			return
+		}
+		row, _ := headerRow(table)
+		result.Warnings = append(result.Warnings, Warning{
+			Kind:   WarningUnexpectedHeader,
+			Table:  TableImportant,
+			Detail: fmt.Sprintf("无法识别第%d个 table.cjsj_tab2，表头为 [%s]", i+1, strings.Join(strings.Fields(row.Text()), " ")),
+		})
+	})
+	if !foundRates {
+		result.Warnings = append(result.Warnings, Warning{Kind: WarningMissingTable, Table: TableRates, Detail: "找不到央行利率表"})
+	}
+
+	return result, nil
+}
+
+// 解析财经日历表
+func parseCalendarTable(table *goquery.Selection, result *Result) {
+	columns, headerIndex, warnings := mapColumns(TableCalendar, table, calendarHeaders)
+	result.Warnings = append(result.Warnings, warnings...)
+	if !columns.has(calendarRequired...) {
+		return
+	}
+
+	table.Find("tr").Each(func(i int, tr *goquery.Selection) {
 		// 跳过表头
-		if i == 0 {
+		if i <= headerIndex {
 			return
 		}
 
 		cells := tr.Find("td")
-		if cells.Length() >= 7 {
-			event := CalendarEvent{
-				Time:        strings.TrimSpace(cells.Eq(0).Text()),
-				Region:      strings.TrimSpace(cells.Eq(1).Text()),
-				Indicator:   strings.TrimSpace(cells.Eq(2).Text()),
-				Previous:    strings.TrimSpace(cells.Eq(3).Text()),
-				Forecast:    strings.TrimSpace(cells.Eq(4).Text()),
-				Actual:      strings.TrimSpace(cells.Eq(5).Text()),
-				Importance:  strings.TrimSpace(cells.Eq(6).Text()),
-				Impact:      strings.TrimSpace(cells.Eq(7).Text()),
-				Description: strings.TrimSpace(cells.Eq(8).Text()),
-			}
-			if event.Time != "" && event.Indicator != "" {
-				events = append(events, event)
-			}
+		// 单个单元格的行是"今日无数据"之类的提示
+		if cells.Length() <= 1 {
+			return
+		}
+		if cells.Length() < columns.width() {
+			result.Warnings = append(result.Warnings, shortRow(TableCalendar, i, cells.Length(), columns.width()))
+		}
+
+		event := CalendarEvent{
+			Time:        columns.get(cells, ColTime),
+			Region:      columns.get(cells, ColRegion),
+			Indicator:   columns.get(cells, ColIndicator),
+			Previous:    columns.get(cells, ColPrevious),
+			Forecast:    columns.get(cells, ColForecast),
+			Actual:      columns.get(cells, ColActual),
+			Importance:  columns.get(cells, ColImportance),
+			Impact:      columns.get(cells, ColImpact),
+			Description: columns.get(cells, ColDescription),
+		}
+		if event.Time != "" && event.Indicator != "" {
+			event.ParseValues()
+			result.Events = append(result.Events, event)
 		}
 	})
+}
 
-	// 解析重要事件
-	doc.Find("table.cjsj_tab2 tr").Each(func(i int, tr *goquery.Selection) {
+// 解析重要事件表
+func parseImportantTable(table *goquery.Selection, result *Result) {
+	columns, headerIndex, warnings := mapColumns(TableImportant, table, importantHeaders)
+	result.Warnings = append(result.Warnings, warnings...)
+
+	table.Find("tr").Each(func(i int, tr *goquery.Selection) {
 		// 跳过表头和无效行
-		if i <= 1 || !strings.Contains(tr.Text(), "|") {
+		if i <= headerIndex || !strings.Contains(tr.Text(), "|") {
 			return
 		}
 
 		cells := tr.Find("td")
-		if cells.Length() >= 5 {
-			event := ImportantEvent{
-				Time:       strings.TrimSpace(cells.Eq(0).Text()),
-				Region:     strings.TrimSpace(cells.Eq(1).Text()),
-				Location:   strings.TrimSpace(cells.Eq(2).Text()),
-				Importance: strings.TrimSpace(cells.Eq(3).Text()),
-				Event:      strings.TrimSpace(cells.Eq(4).Text()),
-			}
-			if event.Time != "" && event.Event != "" {
-				importantEvents = append(importantEvents, event)
-			}
+		if cells.Length() < columns.width() {
+			result.Warnings = append(result.Warnings, shortRow(TableImportant, i, cells.Length(), columns.width()))
+		}
+
+		event := ImportantEvent{
+			Time:       columns.get(cells, ColTime),
+			Region:     columns.get(cells, ColEventRegion),
+			Location:   columns.get(cells, ColLocation),
+			Importance: columns.get(cells, ColImportance),
+			Event:      columns.get(cells, ColEvent),
+		}
+		if event.Time != "" && event.Event != "" {
+			result.ImportantEvents = append(result.ImportantEvents, event)
 		}
 	})
+}
+
+// 解析央行利率表
+func parseRateTable(table *goquery.Selection, result *Result) {
+	columns, headerIndex, warnings := mapColumns(TableRates, table, rateHeaders)
+	result.Warnings = append(result.Warnings, warnings...)
 
-	// 解析央行利率信息
-	doc.Find("table.cjsj_tab2").Last().Find("tr").Each(func(i int, tr *goquery.Selection) {
+	table.Find("tr").Each(func(i int, tr *goquery.Selection) {
 		// 跳过表头
-		if i == 0 {
+		if i <= headerIndex {
 			return
 		}
 
 		cells := tr.Find("td")
-		if cells.Length() >= 9 {
-			rate := CentralBankRate{
-				Bank:         strings.TrimSpace(cells.Eq(0).Text()),
-				RateName:     strings.TrimSpace(cells.Eq(1).Text()),
-				CurrentRate:  strings.TrimSpace(cells.Eq(2).Text()),
-				PreviousRate: strings.TrimSpace(cells.Eq(3).Text()),
-				LastChange:   strings.TrimSpace(cells.Eq(4).Text()),
-				HistoryHigh:  strings.TrimSpace(cells.Eq(5).Text()),
-				HistoryLow:   strings.TrimSpace(cells.Eq(6).Text()),
-				NextForecast: strings.TrimSpace(cells.Eq(7).Text()),
-				LatestCPI:   strings.TrimSpace(cells.Eq(8).Text()),
-			}
-			if rate.Bank != "" && rate.RateName != "" {
-				rates = append(rates, rate)
-			}
+		if cells.Length() <= 1 {
+			return
+		}
+		if cells.Length() < columns.width() {
+			result.Warnings = append(result.Warnings, shortRow(TableRates, i, cells.Length(), columns.width()))
 		}
-	})
 
-	return events, importantEvents, rates, nil
+		rate := CentralBankRate{
+			Bank:         columns.get(cells, ColBank),
+			RateName:     columns.get(cells, ColRateName),
+			CurrentRate:  columns.get(cells, ColCurrentRate),
+			PreviousRate: columns.get(cells, ColPreviousRate),
+			LastChange:   columns.get(cells, ColLastChange),
+			HistoryHigh:  columns.get(cells, ColHistoryHigh),
+			HistoryLow:   columns.get(cells, ColHistoryLow),
+			NextForecast: columns.get(cells, ColNextForecast),
+			LatestCPI:    columns.get(cells, ColLatestCPI),
+		}
+		if rate.Bank != "" && rate.RateName != "" {
+			result.Rates = append(result.Rates, rate)
+		}
+	})
 }
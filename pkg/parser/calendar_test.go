@@ -0,0 +1,251 @@
+package parser
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "更新 testdata/golden 中的期望输出")
+
+func TestParseFinancialCalendarGolden(t *testing.T) {
+	pages, err := filepath.Glob(filepath.Join("..", "..", "testdata", "fx678", "*.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) == 0 {
+		t.Fatal("testdata/fx678 中没有页面")
+	}
+
+	for _, page := range pages {
+		name := strings.TrimSuffix(filepath.Base(page), ".html")
+		t.Run(name, func(t *testing.T) {
+			html, err := os.ReadFile(page)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := Parse(string(html))
+			if err != nil {
+				t.Fatalf("解析失败: %v", err)
+			}
+			got, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("..", "..", "testdata", "golden", name+".json")
+			if *update {
+				if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("读取golden文件失败（使用 -update 生成）: %v", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("解析结果与 %s 不一致，确认后使用 -update 更新\n得到:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestParseFinancialCalendarRegularDay(t *testing.T) {
+	html, err := os.ReadFile(filepath.Join("..", "..", "testdata", "fx678", "20250205.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, importantEvents, rates, err := ParseFinancialCalendar(string(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) == 0 || len(importantEvents) == 0 || len(rates) == 0 {
+		t.Fatalf("常规页面应解析出全部三类数据: events=%d important=%d rates=%d",
+			len(events), len(importantEvents), len(rates))
+	}
+
+	adp := events[3]
+	if adp.Region != "美国" || adp.Actual != "18.3" || adp.Importance != "高" || adp.Impact != "利多 美元" {
+		t.Errorf("ADP 事件字段映射错误: %+v", adp)
+	}
+}
+
+func TestParseDetectsLayoutDrift(t *testing.T) {
+	tests := []struct {
+		page          string
+		wantKinds     []WarningKind
+		layoutChanged bool
+	}{
+		{"20250205", nil, false},
+		{"20250208", nil, false},
+		{"layout_renamed_class", []WarningKind{WarningMissingTable}, true},
+		{"layout_reordered_columns", nil, false},
+		{"layout_extra_column", []WarningKind{WarningUnknownColumn}, false},
+		// 表头和数据行同时缺少可选列：数据行与表头一致，只报告表头缺列
+		{"layout_short_rows", []WarningKind{WarningUnexpectedHeader}, true},
+		{"layout_short_rows_full_header", []WarningKind{WarningShortRow}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.page, func(t *testing.T) {
+			html, err := os.ReadFile(filepath.Join("..", "..", "testdata", "fx678", tt.page+".html"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Parse(string(html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			kinds := make(map[WarningKind]bool)
+			for _, w := range result.Warnings {
+				kinds[w.Kind] = true
+			}
+			if len(tt.wantKinds) == 0 && len(result.Warnings) > 0 {
+				t.Errorf("不应有警告: %v", result.Warnings)
+			}
+			for _, kind := range tt.wantKinds {
+				if !kinds[kind] {
+					t.Errorf("缺少 %s 警告: %v", kind, result.Warnings)
+				}
+			}
+			if got := LayoutChanged(result.Warnings); got != tt.layoutChanged {
+				t.Errorf("LayoutChanged = %v, 期望 %v", got, tt.layoutChanged)
+			}
+		})
+	}
+}
+
+// 列顺序变化、新增列或使用别名时，解析结果应与常规页面一致
+func TestParseMapsColumnsByHeader(t *testing.T) {
+	parse := func(page string) *Result {
+		t.Helper()
+		html, err := os.ReadFile(filepath.Join("..", "..", "testdata", "fx678", page+".html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Parse(string(html))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	want := parse("20250205")
+	for _, page := range []string{"layout_reordered_columns", "layout_extra_column"} {
+		got := parse(page)
+		if !reflect.DeepEqual(got.Events, want.Events) {
+			t.Errorf("%s 的财经日历事件与常规页面不一致:\n%+v", page, got.Events)
+		}
+		if !reflect.DeepEqual(got.Rates, want.Rates) {
+			t.Errorf("%s 的央行利率与常规页面不一致", page)
+		}
+	}
+}
+
+func TestRegisterColumnAliases(t *testing.T) {
+	html := `<table class="cjsj_tab">
+		<tr><th>时间</th><th>地区</th><th>事件名称</th><th>前值</th><th>预测</th><th>公布值</th><th>重要性</th><th>利多利空</th><th>解读</th></tr>
+		<tr><td>21:30</td><td>美国</td><td>1月CPI年率</td><td>2.9%</td><td>2.9%</td><td>3.0%</td><td>高</td><td>利多 美元</td><td>解读</td></tr>
+	</table>`
+
+	result, err := Parse(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 0 {
+		t.Fatalf("未注册别名时缺少指标列，不应解析出事件: %+v", result.Events)
+	}
+
+	t.Cleanup(func() { RegisterColumnAliases(nil) })
+	// 重新加载配置时会再次注册，别名不应累积
+	for i := 0; i < 2; i++ {
+		RegisterColumnAliases(map[string][]string{ColIndicator: {"事件名称"}})
+	}
+	aliasMu.RLock()
+	aliases := append([]string(nil), columnAliases[ColIndicator]...)
+	aliasMu.RUnlock()
+	if want := append(append([]string(nil), DefaultColumnAliases[ColIndicator]...), "事件名称"); !reflect.DeepEqual(aliases, want) {
+		t.Errorf("重复注册后的别名 = %q, 期望 %q", aliases, want)
+	}
+
+	result, err = Parse(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 || result.Events[0].Indicator != "1月CPI年率" {
+		t.Errorf("注册别名后应按别名映射列: %+v", result.Events)
+	}
+
+	// 配置中删除的别名不再生效，内置别名保留
+	RegisterColumnAliases(map[string][]string{})
+	result, err = Parse(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 0 {
+		t.Errorf("删除别名后不应再按别名映射列: %+v", result.Events)
+	}
+	if _, ok := canonicalColumn("经济指标", calendarHeaders); !ok {
+		t.Error("内置别名应保留")
+	}
+}
+
+// 表头和数据行都去掉可选列时，数据行不算列数不足
+func TestParseWithoutOptionalColumn(t *testing.T) {
+	html := `<table class="cjsj_tab">
+		<tr><th>时间</th><th>地区</th><th>指标</th><th>前值</th><th>预测</th><th>公布值</th><th>重要性</th><th>利多利空</th></tr>
+		<tr><td>21:30</td><td>美国</td><td>1月CPI年率</td><td>2.9%</td><td>2.9%</td><td>3.0%</td><td>高</td><td>利多 美元</td></tr>
+	</table>`
+
+	result, err := Parse(html)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Events) != 1 {
+		t.Fatalf("应解析出事件: %+v", result.Events)
+	}
+	for _, w := range result.Warnings {
+		if w.Kind == WarningShortRow {
+			t.Errorf("不应报告列数不足: %v", w)
+		}
+	}
+}
+
+func TestSeriesName(t *testing.T) {
+	tests := map[string]string{
+		"1月季调后非农就业人口(万)":   "季调后非农就业人口(万)",
+		"12月贸易帐(亿美元)":      "贸易帐(亿美元)",
+		"第四季度GDP年化季率初值":    "GDP年化季率初值",
+		"2月1日当周初请失业金人数(万)": "初请失业金人数(万)",
+		"当周EIA原油库存(万桶)":    "EIA原油库存(万桶)",
+		"欧元区央行利率决议":        "欧元区央行利率决议",
+	}
+	for indicator, want := range tests {
+		if got := SeriesName(indicator); got != want {
+			t.Errorf("SeriesName(%q) = %q, 期望 %q", indicator, got, want)
+		}
+	}
+}
+
+func TestParseImportance(t *testing.T) {
+	for name, want := range map[string]string{"high": "高", "Medium": "中", "低": "低", "all": "", "": ""} {
+		if got, err := ParseImportance(name); err != nil || got != want {
+			t.Errorf("ParseImportance(%q) = %q, %v, 期望 %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseImportance("urgent"); err == nil {
+		t.Error("未知的重要性应返回错误")
+	}
+}
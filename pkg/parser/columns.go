@@ -0,0 +1,212 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// 财经日历表的列名
+const (
+	ColTime        = "时间"
+	ColRegion      = "地区"
+	ColIndicator   = "指标"
+	ColPrevious    = "前值"
+	ColForecast    = "预测"
+	ColActual      = "公布值"
+	ColImportance  = "重要性"
+	ColImpact      = "利多利空"
+	ColDescription = "解读"
+)
+
+// 重要事件表的列名（时间、重要性与财经日历表相同）
+const (
+	ColEventRegion = "国家地区"
+	ColLocation    = "地点"
+	ColEvent       = "事件"
+)
+
+// 央行利率表的列名
+const (
+	ColBank         = "央行"
+	ColRateName     = "利率名称"
+	ColCurrentRate  = "当前值"
+	ColPreviousRate = "前次值"
+	ColLastChange   = "最近非0变动基点"
+	ColHistoryHigh  = "历史峰值"
+	ColHistoryLow   = "历史最低"
+	ColNextForecast = "下次预测值"
+	ColLatestCPI    = "CPI最新值"
+)
+
+// 各表格的标准列名与必需列
+var (
+	calendarHeaders  = []string{ColTime, ColRegion, ColIndicator, ColPrevious, ColForecast, ColActual, ColImportance, ColImpact, ColDescription}
+	importantHeaders = []string{ColTime, ColEventRegion, ColLocation, ColImportance, ColEvent}
+	rateHeaders      = []string{ColBank, ColRateName, ColCurrentRate, ColPreviousRate, ColLastChange, ColHistoryHigh, ColHistoryLow, ColNextForecast, ColLatestCPI}
+
+	calendarRequired  = []string{ColTime, ColIndicator}
+	importantRequired = []string{ColTime, ColEvent}
+	rateRequired      = []string{ColBank, ColRateName}
+)
+
+// DefaultColumnAliases 内置的列名别名：标准列名 -> 页面上可能出现的其它写法
+var DefaultColumnAliases = map[string][]string{
+	ColRegion:       {"国家地区", "国家/地区", "国家"},
+	ColIndicator:    {"指标名称", "经济指标"},
+	ColForecast:     {"预测值", "预期", "预期值"},
+	ColActual:       {"今值", "实际值", "公布"},
+	ColImpact:       {"利多/利空", "影响"},
+	ColDescription:  {"数据解读"},
+	ColEventRegion:  {"地区", "国家/地区", "国家"},
+	ColEvent:        {"事件内容"},
+	ColCurrentRate:  {"当前利率"},
+	ColPreviousRate: {"前值"},
+	ColNextForecast: {"下次预测"},
+}
+
+var (
+	aliasMu       sync.RWMutex
+	columnAliases = copyAliases(DefaultColumnAliases)
+)
+
+func copyAliases(aliases map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(aliases))
+	for name, list := range aliases {
+		result[name] = append([]string(nil), list...)
+	}
+	return result
+}
+
+// RegisterColumnAliases 设置内置别名之外的列名别名，通常来自配置文件。
+// 每次调用替换之前注册的别名，重复注册同一配置不会累积
+func RegisterColumnAliases(aliases map[string][]string) {
+	result := copyAliases(DefaultColumnAliases)
+	for name, list := range aliases {
+		for _, alias := range list {
+			result[name] = append(result[name], normalizeHeader(alias))
+		}
+	}
+
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	columnAliases = result
+}
+
+// 去除表头文本中的所有空白
+func normalizeHeader(s string) string {
+	return strings.Join(strings.Fields(s), "")
+}
+
+// 在给定的标准列名范围内，将页面表头解析为标准列名
+func canonicalColumn(header string, names []string) (string, bool) {
+	header = normalizeHeader(header)
+	for _, name := range names {
+		if header == name {
+			return name, true
+		}
+	}
+
+	aliasMu.RLock()
+	defer aliasMu.RUnlock()
+	for _, name := range names {
+		for _, alias := range columnAliases[name] {
+			if header == alias {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// columnMap 记录标准列名在数据行中的位置
+type columnMap map[string]int
+
+// 读取数据行中指定列的文本，列不存在或数据行过短时返回空字符串
+func (m columnMap) get(cells *goquery.Selection, name string) string {
+	i, ok := m[name]
+	if !ok || i >= cells.Length() {
+		return ""
+	}
+	return strings.TrimSpace(cells.Eq(i).Text())
+}
+
+// 数据行期望的列数，即表头中最靠后的已识别列加一
+func (m columnMap) width() int {
+	width := 0
+	for _, i := range m {
+		if i+1 > width {
+			width = i + 1
+		}
+	}
+	return width
+}
+
+func (m columnMap) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := m[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// 表头所在的行：优先取含 th 的第一行，否则取第一行
+func headerRow(table *goquery.Selection) (*goquery.Selection, int) {
+	index := -1
+	table.Find("tr").EachWithBreak(func(i int, tr *goquery.Selection) bool {
+		if tr.Find("th").Length() > 0 {
+			index = i
+			return false
+		}
+		return true
+	})
+	if index < 0 {
+		index = 0
+	}
+	return table.Find("tr").Eq(index), index
+}
+
+// 读取表头并按表头文本建立列映射，返回映射、表头行号以及警告
+func mapColumns(tableName string, table *goquery.Selection, names []string) (columnMap, int, []Warning) {
+	row, index := headerRow(table)
+
+	var headers []string
+	row.Find("th, td").Each(func(i int, cell *goquery.Selection) {
+		headers = append(headers, strings.TrimSpace(cell.Text()))
+	})
+
+	columns := make(columnMap)
+	var warnings []Warning
+	for i, header := range headers {
+		name, ok := canonicalColumn(header, names)
+		if !ok {
+			warnings = append(warnings, Warning{
+				Kind:   WarningUnknownColumn,
+				Table:  tableName,
+				Detail: fmt.Sprintf("第%d列表头 %q 无法识别，已忽略", i+1, header),
+			})
+			continue
+		}
+		if _, dup := columns[name]; !dup {
+			columns[name] = i
+		}
+	}
+
+	var missing []string
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		warnings = append(warnings, Warning{
+			Kind:   WarningUnexpectedHeader,
+			Table:  tableName,
+			Detail: fmt.Sprintf("表头为 [%s]，缺少列 [%s]", strings.Join(headers, " "), strings.Join(missing, " ")),
+		})
+	}
+	return columns, index, warnings
+}
@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// 页面上的时间，例如 "21:30"、"次日02:00"
+var clockPattern = regexp.MustCompile(`^\s*(次日)?\s*(\d{1,2}):(\d{2})\s*$`)
+
+// ResolveTime 将页面上的时间文本与所属日期组合为完整时间，
+// 使用 day 的时区；"待定"等无法识别的文本返回 false
+func ResolveTime(day time.Time, text string) (time.Time, bool) {
+	m := clockPattern.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}, false
+	}
+	hour, _ := strconv.Atoi(m[2])
+	minute, _ := strconv.Atoi(m[3])
+	if hour > 24 || minute > 59 {
+		return time.Time{}, false
+	}
+
+	t := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+	if m[1] != "" {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, true
+}
+
+// SetDate 根据页面所属日期为事件填充完整时间
+func (r *Result) SetDate(day time.Time) {
+	for i := range r.Events {
+		r.Events[i].At, _ = ResolveTime(day, r.Events[i].Time)
+	}
+	for i := range r.ImportantEvents {
+		r.ImportantEvents[i].At, _ = ResolveTime(day, r.ImportantEvents[i].Time)
+	}
+}
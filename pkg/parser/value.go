@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit 表示数值的单位
+type Unit string
+
+const (
+	UnitNone    Unit = ""
+	UnitPercent Unit = "%"
+	UnitK       Unit = "K"
+	UnitWan     Unit = "万"
+	UnitM       Unit = "M"
+	UnitYi      Unit = "亿"
+	UnitB       Unit = "B"
+	UnitWanYi   Unit = "万亿"
+)
+
+// 各单位对应的倍数，百分比按百分点计
+var unitScale = map[Unit]float64{
+	UnitNone:    1,
+	UnitPercent: 1,
+	UnitK:       1e3,
+	UnitWan:     1e4,
+	UnitM:       1e6,
+	UnitYi:      1e8,
+	UnitB:       1e9,
+	UnitWanYi:   1e12,
+}
+
+// Value 表示前值、预测值或公布值解析后的数值
+type Value struct {
+	Raw     string  `json:"raw"`     // 原始文本
+	Number  float64 `json:"number"`  // 按原单位表示的数值
+	Unit    Unit    `json:"unit"`    // 单位
+	Revised bool    `json:"revised"` // 是否带有修正标记
+	Missing bool    `json:"missing"` // 是否缺失（未公布、无预测等）
+}
+
+var (
+	numberPattern   = regexp.MustCompile(`[-+]?\d+(?:,\d{3})*(?:\.\d+)?|[-+]?\.\d+`)
+	revisionPattern = regexp.MustCompile(`修正|\(修\)|（修）|\*|↑|↓|[0-9%]r$`)
+	hintPattern     = regexp.MustCompile(`[（(](万亿|万|亿|千)[^）)]*[）)]\s*$`)
+	missingValues   = map[string]bool{"": true, "-": true, "--": true, "—": true, "——": true, "N/A": true, "n/a": true, "待公布": true, "暂无": true}
+)
+
+// ParseValue 解析数值文本，例如 "3.2%"、"25.6万"、"-0.1"、"1.2K"
+func ParseValue(raw string) Value {
+	return parseValue(raw, UnitNone)
+}
+
+// 解析数值文本，文本本身没有单位时使用 hint
+func parseValue(raw string, hint Unit) Value {
+	v := Value{Raw: raw}
+	text := strings.TrimSpace(raw)
+	if missingValues[text] {
+		v.Missing = true
+		return v
+	}
+
+	v.Revised = revisionPattern.MatchString(text)
+
+	loc := numberPattern.FindStringIndex(text)
+	if loc == nil {
+		v.Missing = true
+		return v
+	}
+	number, err := strconv.ParseFloat(strings.ReplaceAll(text[loc[0]:loc[1]], ",", ""), 64)
+	if err != nil {
+		v.Missing = true
+		return v
+	}
+	v.Number = number
+	v.Unit = parseUnit(text[loc[1]:])
+	if v.Unit == UnitNone {
+		v.Unit = hint
+	}
+	return v
+}
+
+// 识别紧跟在数字后面的单位
+func parseUnit(suffix string) Unit {
+	suffix = strings.TrimSpace(suffix)
+	switch {
+	case strings.HasPrefix(suffix, "%"), strings.HasPrefix(suffix, "％"):
+		return UnitPercent
+	case strings.HasPrefix(suffix, "万亿"):
+		return UnitWanYi
+	case strings.HasPrefix(suffix, "万"):
+		return UnitWan
+	case strings.HasPrefix(suffix, "亿"):
+		return UnitYi
+	case strings.HasPrefix(suffix, "千"), strings.HasPrefix(suffix, "K"), strings.HasPrefix(suffix, "k"):
+		return UnitK
+	case strings.HasPrefix(suffix, "M"):
+		return UnitM
+	case strings.HasPrefix(suffix, "B"):
+		return UnitB
+	}
+	return UnitNone
+}
+
+// 从指标名称末尾的括号中读取单位，例如 "非农就业人口(万)"、"贸易帐(亿美元)"
+func unitHint(indicator string) Unit {
+	m := hintPattern.FindStringSubmatch(indicator)
+	if m == nil {
+		return UnitNone
+	}
+	if m[1] == "千" {
+		return UnitK
+	}
+	return Unit(m[1])
+}
+
+// Scale 返回单位对应的倍数，未知单位按 1 计
+func (u Unit) Scale() float64 {
+	scale, ok := unitScale[u]
+	if !ok {
+		return 1
+	}
+	return scale
+}
+
+// Scaled 返回换算单位后的数值，百分比保持百分点
+func (v Value) Scaled() float64 {
+	return v.Number * v.Unit.Scale()
+}
+
+// Comparable 判断两个数值能否直接比较：都不缺失，且都是百分比或都不是百分比
+func (v Value) Comparable(o Value) bool {
+	if v.Missing || o.Missing {
+		return false
+	}
+	return (v.Unit == UnitPercent) == (o.Unit == UnitPercent)
+}
+
+// ParseValues 根据原始文本填充事件的前值、预测值和公布值
+func (e *CalendarEvent) ParseValues() {
+	hint := unitHint(e.Indicator)
+	e.PreviousValue = parseValue(e.Previous, hint)
+	e.ForecastValue = parseValue(e.Forecast, hint)
+	e.ActualValue = parseValue(e.Actual, hint)
+}
@@ -0,0 +1,56 @@
+package parser
+
+import "testing"
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Value
+	}{
+		{"3.2%", Value{Raw: "3.2%", Number: 3.2, Unit: UnitPercent}},
+		{"-0.1", Value{Raw: "-0.1", Number: -0.1}},
+		{"25.6万", Value{Raw: "25.6万", Number: 25.6, Unit: UnitWan}},
+		{"-966亿", Value{Raw: "-966亿", Number: -966, Unit: UnitYi}},
+		{"1.2万亿", Value{Raw: "1.2万亿", Number: 1.2, Unit: UnitWanYi}},
+		{"215K", Value{Raw: "215K", Number: 215, Unit: UnitK}},
+		{"1.5M", Value{Raw: "1.5M", Number: 1.5, Unit: UnitM}},
+		{"3B", Value{Raw: "3B", Number: 3, Unit: UnitB}},
+		{"1,234.5", Value{Raw: "1,234.5", Number: 1234.5}},
+		{" 4.1% ", Value{Raw: " 4.1% ", Number: 4.1, Unit: UnitPercent}},
+		{"3.1%(修正)", Value{Raw: "3.1%(修正)", Number: 3.1, Unit: UnitPercent, Revised: true}},
+		{"52.2r", Value{Raw: "52.2r", Number: 52.2, Revised: true}},
+		{"", Value{Raw: "", Missing: true}},
+		{"--", Value{Raw: "--", Missing: true}},
+		{"待公布", Value{Raw: "待公布", Missing: true}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseValue(tt.raw); got != tt.want {
+			t.Errorf("ParseValue(%q) = %+v, 期望 %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestValueScaled(t *testing.T) {
+	if got := ParseValue("25.6万").Scaled(); got != 256000 {
+		t.Errorf("25.6万 换算为 %v", got)
+	}
+	if got := ParseValue("3.2%").Scaled(); got != 3.2 {
+		t.Errorf("百分比应保持百分点: %v", got)
+	}
+}
+
+func TestCalendarEventParseValuesUsesIndicatorUnit(t *testing.T) {
+	event := CalendarEvent{Indicator: "1月季调后非农就业人口(万)", Previous: "25.6", Forecast: "17", Actual: "14.3"}
+	event.ParseValues()
+
+	if event.ActualValue.Unit != UnitWan || event.ActualValue.Scaled() != 143000 {
+		t.Errorf("应从指标名称读取单位: %+v", event.ActualValue)
+	}
+	if !event.ForecastValue.Comparable(event.ActualValue) {
+		t.Error("同单位的预测值和公布值应可比较")
+	}
+	if ParseValue("3.2%").Comparable(ParseValue("3.2")) {
+		t.Error("百分比与非百分比不应直接比较")
+	}
+}
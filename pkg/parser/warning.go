@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// WarningKind 表示解析警告的类型
+type WarningKind string
+
+const (
+	// WarningMissingTable 页面中找不到预期的表格
+	WarningMissingTable WarningKind = "missing_table"
+	// WarningUnexpectedHeader 表头与预期的列名不一致
+	WarningUnexpectedHeader WarningKind = "unexpected_header"
+	// WarningShortRow 数据行的列数少于预期
+	WarningShortRow WarningKind = "short_row"
+	// WarningUnknownColumn 表头中有无法识别的列，该列被忽略
+	WarningUnknownColumn WarningKind = "unknown_column"
+)
+
+// 表格名称
+const (
+	TableCalendar  = "财经日历"
+	TableImportant = "重要事件"
+	TableRates     = "央行利率"
+)
+
+// Warning 表示一条结构化的解析警告
+type Warning struct {
+	Kind   WarningKind `json:"kind"`
+	Table  string      `json:"table"`
+	Row    int         `json:"row"`
+	Detail string      `json:"detail"`
+}
+
+func (w Warning) String() string {
+	if w.Row > 0 {
+		return fmt.Sprintf("%s表第%d行: %s", w.Table, w.Row, w.Detail)
+	}
+	return fmt.Sprintf("%s表: %s", w.Table, w.Detail)
+}
+
+// LayoutChanged 判断警告是否说明数据源页面结构发生了变化
+func LayoutChanged(warnings []Warning) bool {
+	for _, w := range warnings {
+		if w.Kind == WarningMissingTable || w.Kind == WarningUnexpectedHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// 生成列数不足的警告
+func shortRow(tableName string, row, got, want int) Warning {
+	return Warning{
+		Kind:   WarningShortRow,
+		Table:  tableName,
+		Row:    row,
+		Detail: fmt.Sprintf("只有%d列，期望%d列", got, want),
+	}
+}
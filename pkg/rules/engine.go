@@ -0,0 +1,66 @@
+package rules
+
+import "time"
+
+// Hit 表示一个事件命中了一条规则
+type Hit struct {
+	Rule *Rule
+	Item Item
+	Key  string // 规则+事件的索引键
+	New  bool   // 是否首次命中，只有首次命中才执行响铃、提醒和日志动作
+}
+
+// Engine 在每次刷新时用全部规则匹配事件，并记录已命中的规则+事件以去重
+type Engine struct {
+	Rules    []*Rule
+	Location *time.Location // 时间窗口所用的时区
+
+	fired map[string]time.Time // 已命中的规则+事件及其日期
+}
+
+// NewEngine 返回使用 rules 的规则引擎
+func NewEngine(rules []*Rule, loc *time.Location) *Engine {
+	if loc == nil {
+		loc = time.Local
+	}
+	return &Engine{Rules: rules, Location: loc, fired: make(map[string]time.Time)}
+}
+
+// 规则+事件的索引键
+func hitKey(rule string, day time.Time, it Item) string {
+	region, name, _, _ := it.fields()
+	var eventTime string
+	if it.Calendar != nil {
+		eventTime = it.Calendar.Time
+	} else {
+		eventTime = it.Important.Time
+	}
+	return rule + "|" + day.Format("2006-01-02") + "|" + eventTime + "|" + region + "|" + name
+}
+
+// Evaluate 匹配某一天的全部事件，返回所有命中（包括之前已命中过的，用于高亮）
+func (e *Engine) Evaluate(day time.Time, items []Item) []Hit {
+	var hits []Hit
+	for _, it := range items {
+		for _, r := range e.Rules {
+			if !r.Match(it, e.Location) {
+				continue
+			}
+			key := hitKey(r.Name, day, it)
+			_, seen := e.fired[key]
+			hits = append(hits, Hit{Rule: r, Item: it, Key: key, New: !seen})
+			e.fired[key] = day
+		}
+	}
+	return hits
+}
+
+// Prune 清除 before 之前日期的命中记录，避免长时间运行时无限增长。
+// 被清除日期的事件再次匹配时会重新视为首次命中
+func (e *Engine) Prune(before time.Time) {
+	for key, day := range e.fired {
+		if day.Before(before) {
+			delete(e.fired, key)
+		}
+	}
+}
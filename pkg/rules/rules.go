@@ -0,0 +1,204 @@
+// Package rules 按用户定义的规则匹配财经日历事件和重要事件
+package rules
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/surprise"
+)
+
+// Action 表示规则命中后的动作
+type Action string
+
+const (
+	ActionHighlight Action = "highlight" // 在界面中高亮
+	ActionSound     Action = "sound"     // 终端响铃
+	ActionNotify    Action = "notify"    // 通过提醒渠道发送
+	ActionLog       Action = "log"       // 写入日志
+)
+
+// Rule 是编译后的规则，未设置的条件不参与匹配
+type Rule struct {
+	Name       string
+	Regions    map[string]bool
+	Indicator  *regexp.Regexp
+	Importance map[string]bool
+	Impact     surprise.Direction
+	Surprise   float64
+	// 时间窗口，以当天零点起的分钟数表示，From > To 时跨越午夜
+	HasWindow bool
+	From, To  int
+	Actions   map[Action]bool
+}
+
+// Compile 校验并编译配置中的规则
+func Compile(configs []config.RuleConfig) ([]*Rule, error) {
+	var rules []*Rule
+	names := make(map[string]bool)
+	for i, c := range configs {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("规则%d", i+1)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("规则名称重复: %s", name)
+		}
+		names[name] = true
+
+		r, err := compile(name, c)
+		if err != nil {
+			return nil, fmt.Errorf("规则 %s: %v", name, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+func compile(name string, c config.RuleConfig) (*Rule, error) {
+	r := &Rule{Name: name, Surprise: c.Surprise}
+
+	if len(c.Regions) > 0 {
+		r.Regions = make(map[string]bool)
+		for _, region := range c.Regions {
+			r.Regions[region] = true
+		}
+	}
+	if c.Indicator != "" {
+		re, err := regexp.Compile(c.Indicator)
+		if err != nil {
+			return nil, fmt.Errorf("无效的指标正则表达式: %v", err)
+		}
+		r.Indicator = re
+	}
+	if len(c.Importance) > 0 {
+		r.Importance = make(map[string]bool)
+		for _, importance := range c.Importance {
+			if importance != "高" && importance != "中" && importance != "低" {
+				return nil, fmt.Errorf("无效的重要性: %s", importance)
+			}
+			r.Importance[importance] = true
+		}
+	}
+	if c.Impact != "" {
+		r.Impact = surprise.Direction(c.Impact)
+		if r.Impact != surprise.DirectionBullish && r.Impact != surprise.DirectionBearish {
+			return nil, fmt.Errorf("无效的影响方向: %s（可选 利多、利空）", c.Impact)
+		}
+	}
+	if c.Surprise < 0 {
+		return nil, fmt.Errorf("意外值阈值不能为负数: %v", c.Surprise)
+	}
+	if c.Window != "" {
+		from, to, err := parseWindow(c.Window)
+		if err != nil {
+			return nil, err
+		}
+		r.HasWindow, r.From, r.To = true, from, to
+	}
+
+	if len(c.Actions) == 0 {
+		return nil, fmt.Errorf("没有设置动作")
+	}
+	r.Actions = make(map[Action]bool)
+	for _, a := range c.Actions {
+		action := Action(a)
+		switch action {
+		case ActionHighlight, ActionSound, ActionNotify, ActionLog:
+			r.Actions[action] = true
+		default:
+			return nil, fmt.Errorf("未知的动作: %s", a)
+		}
+	}
+	return r, nil
+}
+
+// 解析 "HH:MM-HH:MM" 格式的时间窗口
+func parseWindow(window string) (int, int, error) {
+	fromText, toText, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("无效的时间窗口 %q，格式为 HH:MM-HH:MM", window)
+	}
+	from, err := time.Parse("15:04", strings.TrimSpace(fromText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的时间窗口 %q，格式为 HH:MM-HH:MM", window)
+	}
+	to, err := time.Parse("15:04", strings.TrimSpace(toText))
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的时间窗口 %q，格式为 HH:MM-HH:MM", window)
+	}
+	return from.Hour()*60 + from.Minute(), to.Hour()*60 + to.Minute(), nil
+}
+
+// Item 是参与规则匹配的一个事件，Calendar 和 Important 只设置其一
+type Item struct {
+	Calendar  *parser.CalendarEvent
+	Important *parser.ImportantEvent
+	Surprise  *surprise.Surprise // 财经日历事件的意外值，可为 nil
+}
+
+// 事件的地区、名称、重要性和时间
+func (it Item) fields() (region, name, importance string, at time.Time) {
+	if it.Calendar != nil {
+		return it.Calendar.Region, it.Calendar.Indicator, it.Calendar.Importance, it.Calendar.At
+	}
+	return it.Important.Region, it.Important.Event, it.Important.Importance, it.Important.At
+}
+
+// Match 判断事件是否满足规则的全部条件，loc 为时间窗口所用的时区
+func (r *Rule) Match(it Item, loc *time.Location) bool {
+	region, name, importance, at := it.fields()
+
+	if r.Regions != nil && !r.Regions[region] {
+		return false
+	}
+	if r.Indicator != nil && !r.Indicator.MatchString(name) {
+		return false
+	}
+	if r.Importance != nil && !r.Importance[importance] {
+		return false
+	}
+	if r.Impact != surprise.DirectionNone || r.Surprise > 0 {
+		// 影响方向和意外值只对已公布的财经日历事件有意义
+		if it.Calendar == nil || it.Calendar.Actual == "" {
+			return false
+		}
+		if r.Impact != surprise.DirectionNone && surprise.ParseDirection(it.Calendar.Impact) != r.Impact {
+			return false
+		}
+		if r.Surprise > 0 && !exceeds(it.Surprise, r.Surprise) {
+			return false
+		}
+	}
+	if r.HasWindow {
+		if at.IsZero() {
+			return false
+		}
+		local := at.In(loc)
+		minute := local.Hour()*60 + local.Minute()
+		if r.From <= r.To {
+			if minute < r.From || minute > r.To {
+				return false
+			}
+		} else if minute < r.From && minute > r.To {
+			return false
+		}
+	}
+	return true
+}
+
+// 意外值是否超过阈值：有Z分数时比较Z分数，否则比较意外值本身
+func exceeds(s *surprise.Surprise, threshold float64) bool {
+	if s == nil {
+		return false
+	}
+	if s.HasZScore {
+		return math.Abs(s.ZScore) >= threshold
+	}
+	return math.Abs(s.Value) >= threshold
+}
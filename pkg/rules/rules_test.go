@@ -0,0 +1,125 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/config"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/surprise"
+)
+
+var shanghai = time.FixedZone("CST", 8*60*60)
+
+func calendarItem(indicator, importance, actual, impact string, s *surprise.Surprise) Item {
+	return Item{
+		Calendar: &parser.CalendarEvent{
+			Time:       "21:30",
+			Region:     "美国",
+			Indicator:  indicator,
+			Importance: importance,
+			Actual:     actual,
+			Impact:     impact,
+			At:         time.Date(2025, 2, 7, 21, 30, 0, 0, shanghai),
+		},
+		Surprise: s,
+	}
+}
+
+func compileOne(t *testing.T, c config.RuleConfig) *Rule {
+	t.Helper()
+	rules, err := Compile([]config.RuleConfig{c})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules[0]
+}
+
+func TestRuleMatch(t *testing.T) {
+	nfp := calendarItem("1月季调后非农就业人口(万)", "高", "", "", nil)
+	released := calendarItem("1月季调后非农就业人口(万)", "高", "14.3", "利空 美元", &surprise.Surprise{Value: -3.7, ZScore: -2.4, HasZScore: true})
+	cpi := calendarItem("1月CPI年率", "中", "", "", nil)
+	speech := Item{Important: &parser.ImportantEvent{Time: "23:00", Region: "美国", Importance: "高", Event: "美联储主席讲话",
+		At: time.Date(2025, 2, 7, 23, 0, 0, 0, shanghai)}}
+
+	tests := []struct {
+		name string
+		rule config.RuleConfig
+		want []bool // nfp, released, cpi, speech
+	}{
+		{"地区+正则", config.RuleConfig{Regions: []string{"美国"}, Indicator: "非农"}, []bool{true, true, false, false}},
+		{"重要性", config.RuleConfig{Importance: []string{"中"}}, []bool{false, false, true, false}},
+		{"影响方向", config.RuleConfig{Impact: "利空"}, []bool{false, true, false, false}},
+		{"意外Z分数", config.RuleConfig{Surprise: 2}, []bool{false, true, false, false}},
+		{"意外Z分数未超过", config.RuleConfig{Surprise: 3}, []bool{false, false, false, false}},
+		{"重要事件", config.RuleConfig{Indicator: "讲话"}, []bool{false, false, false, true}},
+		{"时间窗口", config.RuleConfig{Window: "22:00-23:30"}, []bool{false, false, false, true}},
+		{"跨午夜窗口", config.RuleConfig{Window: "21:00-01:00"}, []bool{true, true, true, true}},
+	}
+	for _, tt := range tests {
+		tt.rule.Actions = []string{"log"}
+		r := compileOne(t, tt.rule)
+		for i, it := range []Item{nfp, released, cpi, speech} {
+			if got := r.Match(it, shanghai); got != tt.want[i] {
+				t.Errorf("%s: 第%d个事件匹配 = %v, 期望 %v", tt.name, i, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCompileRejectsInvalidRules(t *testing.T) {
+	for _, c := range []config.RuleConfig{
+		{Name: "无动作"},
+		{Name: "未知动作", Actions: []string{"email"}},
+		{Name: "正则", Indicator: "(", Actions: []string{"log"}},
+		{Name: "重要性", Importance: []string{"极高"}, Actions: []string{"log"}},
+		{Name: "方向", Impact: "中性", Actions: []string{"log"}},
+		{Name: "窗口", Window: "21:00", Actions: []string{"log"}},
+	} {
+		if _, err := Compile([]config.RuleConfig{c}); err == nil {
+			t.Errorf("%s: 应返回错误", c.Name)
+		}
+	}
+
+	dup := config.RuleConfig{Name: "a", Actions: []string{"log"}}
+	if _, err := Compile([]config.RuleConfig{dup, dup}); err == nil {
+		t.Error("重复的规则名称应返回错误")
+	}
+}
+
+func TestEngineDeduplicates(t *testing.T) {
+	rules, err := Compile([]config.RuleConfig{{Name: "非农", Indicator: "非农", Actions: []string{"highlight", "sound"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := NewEngine(rules, shanghai)
+	day := time.Date(2025, 2, 7, 0, 0, 0, 0, shanghai)
+	items := []Item{calendarItem("1月季调后非农就业人口(万)", "高", "", "", nil), calendarItem("1月失业率", "高", "", "", nil)}
+
+	hits := engine.Evaluate(day, items)
+	if len(hits) != 1 || !hits[0].New {
+		t.Fatalf("首次刷新应命中一次: %+v", hits)
+	}
+	hits = engine.Evaluate(day, items)
+	if len(hits) != 1 || hits[0].New {
+		t.Fatalf("再次刷新仍应命中（用于高亮）但不是新命中: %+v", hits)
+	}
+	if hits = engine.Evaluate(day.AddDate(0, 0, 1), items); len(hits) != 1 || !hits[0].New {
+		t.Fatalf("其它日期的同名事件应视为新命中: %+v", hits)
+	}
+
+	// 清除时保留 before 当天及之后的记录
+	engine.Prune(day)
+	if hits = engine.Evaluate(day, items); len(hits) != 1 || hits[0].New {
+		t.Fatalf("未被清除的日期不应再次视为新命中: %+v", hits)
+	}
+
+	// 清除指定日期之前的记录
+	engine.Prune(day.AddDate(0, 0, 1))
+	if len(engine.fired) != 1 {
+		t.Errorf("应只保留第二天的命中记录: %v", engine.fired)
+	}
+	if hits = engine.Evaluate(day.AddDate(0, 0, 1), items); len(hits) != 1 || hits[0].New {
+		t.Fatalf("之后日期的命中记录不应被清除: %+v", hits)
+	}
+}
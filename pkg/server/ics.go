@@ -0,0 +1,90 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/ical"
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// DefaultCalendarDays 订阅日历默认包含的天数，maxCalendarDays 为最多的天数
+const (
+	DefaultCalendarDays = 7
+	maxCalendarDays     = 62
+)
+
+// 以 iCalendar 格式提供从 date 起 days 天的财经日历事件和重要事件，供日历应用订阅。
+// 数据库中没有的日期会被跳过
+func (s *Server) calendar(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{"只支持 GET 请求"})
+		return
+	}
+
+	cal, err := s.buildCalendar(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="fmcl.ics"`)
+	if _, err := cal.WriteTo(w); err != nil {
+		logger.Warn("写入响应失败", zap.Error(err))
+	}
+}
+
+func (s *Server) buildCalendar(r *http.Request) (*ical.Calendar, error) {
+	date, err := s.date(r)
+	if err != nil {
+		return nil, err
+	}
+	level, err := parser.ParseImportance(r.URL.Query().Get("importance"))
+	if err != nil {
+		return nil, badRequest("%v", err)
+	}
+	days := DefaultCalendarDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err = strconv.Atoi(value)
+		if err != nil || days <= 0 || days > maxCalendarDays {
+			return nil, badRequest("无效的天数 %q，应为 1 到 %d", value, maxCalendarDays)
+		}
+	}
+
+	cal := ical.New("财经日历", s.Location)
+	from, _ := time.Parse("2006-01-02", date)
+	for i := 0; i < days; i++ {
+		day := from.AddDate(0, 0, i).Format("2006-01-02")
+
+		stored, err := s.DB.CalendarEvents(day)
+		if err != nil {
+			return nil, err
+		}
+		var events []parser.CalendarEvent
+		for _, e := range stored {
+			if level == "" || e.Importance == level {
+				events = append(events, e.CalendarEvent)
+			}
+		}
+		cal.AddEvents(day, events)
+
+		important, err := s.DB.ImportantEvents(day)
+		if err != nil {
+			return nil, err
+		}
+		var filtered []parser.ImportantEvent
+		for _, e := range important {
+			if level == "" || e.Importance == level {
+				filtered = append(filtered, e)
+			}
+		}
+		cal.AddImportantEvents(day, filtered)
+	}
+	return cal, nil
+}
@@ -0,0 +1,248 @@
+// Package server 以 HTTP/JSON 接口提供本地数据库中的财经日历数据
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/logger"
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/source"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+// Server 提供以下只读接口，日期参数为 YYYY-MM-DD 或 YYYYMMDD，默认为数据源时区的今天，
+// 数据库中没有该日期的数据时返回 404:
+//
+//	GET /events?date=&importance=   财经日历事件（importance 为 high/medium/low 或 高/中/低）
+//	GET /important-events?date=     重要事件
+//	GET /rates?date=                央行利率
+//	GET /health                     最近一次获取数据的状态
+//	GET /stream                     以 Server-Sent Events 推送每次获取发现的变化
+//	GET /calendar.ics?date=&days=&importance=  iCalendar 订阅，默认从今天起 7 天
+type Server struct {
+	DB       *storage.DB
+	Location *time.Location // 数据源时区
+	Now      func() time.Time
+
+	mu        sync.Mutex
+	lastFetch time.Time
+	lastError error
+
+	streamMu    sync.Mutex
+	subscribers map[chan message]struct{}
+	nextID      uint64
+}
+
+// New 返回使用 db 的服务器，loc 为数据源时区
+func New(db *storage.DB, loc *time.Location) *Server {
+	return &Server{DB: db, Location: loc, Now: time.Now}
+}
+
+// RecordFetch 记录一次获取数据的结果，用于 /health
+func (s *Server) RecordFetch(at time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastFetch = at
+	s.lastError = err
+}
+
+// Handler 返回全部接口的路由
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", s.get(s.events))
+	mux.HandleFunc("/important-events", s.get(s.importantEvents))
+	mux.HandleFunc("/rates", s.get(s.rates))
+	mux.HandleFunc("/health", s.get(s.health))
+	mux.HandleFunc("/stream", s.stream)
+	mux.HandleFunc("/calendar.ics", s.calendar)
+	return mux
+}
+
+// 请求错误，返回给客户端的状态码和信息
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+func badRequest(format string, args ...interface{}) error {
+	return &requestError{status: http.StatusBadRequest, message: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...interface{}) error {
+	return &requestError{status: http.StatusNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+// 只接受 GET 请求，将处理结果编码为JSON
+func (s *Server) get(handle func(r *http.Request) (int, interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeJSON(w, http.StatusMethodNotAllowed, errorBody{"只支持 GET 请求"})
+			return
+		}
+
+		status, body, err := handle(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		writeJSON(w, status, body)
+	}
+}
+
+// 请求错误返回对应的状态码，其余错误返回 500
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	if reqErr, ok := err.(*requestError); ok {
+		writeJSON(w, reqErr.status, errorBody{reqErr.message})
+		return
+	}
+	logger.Error("处理请求失败", zap.String("path", r.URL.Path), zap.Error(err))
+	writeJSON(w, http.StatusInternalServerError, errorBody{err.Error()})
+}
+
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(body); err != nil {
+		logger.Warn("写入响应失败", zap.Error(err))
+	}
+}
+
+// 解析 date 参数，为空时为数据源时区的今天
+func (s *Server) date(r *http.Request) (string, error) {
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		return s.Now().In(s.Location).Format("2006-01-02"), nil
+	}
+	for _, layout := range source.DateLayouts {
+		if day, err := time.Parse(layout, date); err == nil {
+			return day.Format("2006-01-02"), nil
+		}
+	}
+	return "", badRequest("无效的日期 %q，请使用 YYYY-MM-DD 或 YYYYMMDD", date)
+}
+
+// 解析 date 参数，数据库中没有该日期的数据时返回 404
+func (s *Server) storedDate(r *http.Request) (string, error) {
+	date, err := s.date(r)
+	if err != nil {
+		return "", err
+	}
+	found, err := s.DB.HasDate(date)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", notFound("数据库中没有 %s 的数据", date)
+	}
+	return date, nil
+}
+
+// EventsResponse 是 /events 的响应
+type EventsResponse struct {
+	Date   string                `json:"date"`
+	Events []storage.StoredEvent `json:"events"`
+}
+
+func (s *Server) events(r *http.Request) (int, interface{}, error) {
+	date, err := s.storedDate(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	level, err := parser.ParseImportance(r.URL.Query().Get("importance"))
+	if err != nil {
+		return 0, nil, badRequest("%v", err)
+	}
+
+	stored, err := s.DB.CalendarEvents(date)
+	if err != nil {
+		return 0, nil, err
+	}
+	events := []storage.StoredEvent{}
+	for _, e := range stored {
+		if level == "" || e.Importance == level {
+			events = append(events, e)
+		}
+	}
+	return http.StatusOK, EventsResponse{Date: date, Events: events}, nil
+}
+
+// ImportantEventsResponse 是 /important-events 的响应
+type ImportantEventsResponse struct {
+	Date            string                  `json:"date"`
+	ImportantEvents []parser.ImportantEvent `json:"important_events"`
+}
+
+func (s *Server) importantEvents(r *http.Request) (int, interface{}, error) {
+	date, err := s.storedDate(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	events, err := s.DB.ImportantEvents(date)
+	if err != nil {
+		return 0, nil, err
+	}
+	if events == nil {
+		events = []parser.ImportantEvent{}
+	}
+	return http.StatusOK, ImportantEventsResponse{Date: date, ImportantEvents: events}, nil
+}
+
+// RatesResponse 是 /rates 的响应
+type RatesResponse struct {
+	Date  string                   `json:"date"`
+	Rates []parser.CentralBankRate `json:"rates"`
+}
+
+func (s *Server) rates(r *http.Request) (int, interface{}, error) {
+	date, err := s.storedDate(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	rates, err := s.DB.CentralBankRates(date)
+	if err != nil {
+		return 0, nil, err
+	}
+	if rates == nil {
+		rates = []parser.CentralBankRate{}
+	}
+	return http.StatusOK, RatesResponse{Date: date, Rates: rates}, nil
+}
+
+// HealthResponse 是 /health 的响应。尚未获取过数据或最近一次获取失败时状态码为 503
+type HealthResponse struct {
+	Status    string     `json:"status"` // ok、starting 或 error
+	LastFetch *time.Time `json:"last_fetch,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+func (s *Server) health(r *http.Request) (int, interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case s.lastFetch.IsZero():
+		return http.StatusServiceUnavailable, HealthResponse{Status: "starting"}, nil
+	case s.lastError != nil:
+		at := s.lastFetch
+		return http.StatusServiceUnavailable, HealthResponse{Status: "error", LastFetch: &at, Error: s.lastError.Error()}, nil
+	}
+	at := s.lastFetch
+	return http.StatusOK, HealthResponse{Status: "ok", LastFetch: &at}, nil
+}
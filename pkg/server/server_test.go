@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/storage"
+)
+
+var cst = time.FixedZone("CST", 8*60*60)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	db, err := storage.NewDB(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	events := []parser.CalendarEvent{
+		{Time: "21:15", Region: "美国", Indicator: "1月ADP就业人数(万)", Previous: "12.2", Forecast: "15", Actual: "18.3", Importance: "高"},
+		{Time: "21:30", Region: "美国", Indicator: "12月贸易帐(亿美元)", Previous: "-784", Forecast: "-960", Importance: "中"},
+	}
+	for i := range events {
+		events[i].ParseValues()
+	}
+	if _, err := db.SaveCalendarEvents("2025-02-05", events); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveImportantEvents("2025-02-05", []parser.ImportantEvent{{Time: "17:00", Region: "欧盟", Location: "布鲁塞尔", Importance: "高", Event: "欧盟财长会议"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveCentralBankRates("2025-02-05", []parser.CentralBankRate{{Bank: "美联储", RateName: "联邦基金利率", CurrentRate: "4.50%"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(db, cst)
+	s.Now = func() time.Time { return time.Date(2025, 2, 5, 2, 0, 0, 0, time.UTC) }
+	return s
+}
+
+func get(t *testing.T, s *Server, url string, v interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("%s Content-Type = %q", url, ct)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("%s: %v\n%s", url, err, rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestEvents(t *testing.T) {
+	s := newTestServer(t)
+
+	var resp EventsResponse
+	if code := get(t, s, "/events?date=2025-02-05", &resp); code != http.StatusOK || len(resp.Events) != 2 {
+		t.Fatalf("状态码 %d, 事件 %+v", code, resp.Events)
+	}
+	if resp.Events[0].Surprise == nil || resp.Events[0].Date != "2025-02-05" {
+		t.Errorf("应附带日期和意外值: %+v", resp.Events[0])
+	}
+
+	// 默认日期为数据源时区的今天
+	resp = EventsResponse{}
+	if get(t, s, "/events?importance=high", &resp); resp.Date != "2025-02-05" || len(resp.Events) != 1 || resp.Events[0].Importance != "高" {
+		t.Errorf("按重要性过滤: %+v", resp)
+	}
+
+	// 也接受 YYYYMMDD
+	resp = EventsResponse{}
+	if code := get(t, s, "/events?date=20250205", &resp); code != http.StatusOK || resp.Date != "2025-02-05" || len(resp.Events) != 2 {
+		t.Errorf("YYYYMMDD 格式的日期: %d %+v", code, resp)
+	}
+
+	// 过滤后没有事件时返回空数组
+	resp = EventsResponse{}
+	if get(t, s, "/events?date=2025-02-05&importance=low", &resp); resp.Events == nil || len(resp.Events) != 0 {
+		t.Errorf("没有匹配的事件时应返回空数组: %+v", resp)
+	}
+}
+
+func TestDateNotStored(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, url := range []string{"/events?date=2025-02-06", "/important-events?date=20250206", "/rates?date=2025-02-06"} {
+		var body errorBody
+		if code := get(t, s, url, &body); code != http.StatusNotFound || !strings.Contains(body.Error, "2025-02-06") {
+			t.Errorf("%s: 状态码 %d, 错误 %q", url, code, body.Error)
+		}
+	}
+}
+
+func TestImportantEventsAndRates(t *testing.T) {
+	s := newTestServer(t)
+
+	var events ImportantEventsResponse
+	if code := get(t, s, "/important-events?date=2025-02-05", &events); code != http.StatusOK || len(events.ImportantEvents) != 1 || events.ImportantEvents[0].Event != "欧盟财长会议" {
+		t.Errorf("重要事件: %d %+v", code, events)
+	}
+
+	var rates RatesResponse
+	if code := get(t, s, "/rates?date=2025-02-05", &rates); code != http.StatusOK || len(rates.Rates) != 1 || rates.Rates[0].CurrentRate != "4.50%" {
+		t.Errorf("央行利率: %d %+v", code, rates)
+	}
+}
+
+func TestBadRequests(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, url := range []string{"/events?date=2025-13-05", "/events?importance=urgent", "/rates?date=tomorrow"} {
+		var body errorBody
+		if code := get(t, s, url, &body); code != http.StatusBadRequest || body.Error == "" {
+			t.Errorf("%s: 状态码 %d, 错误 %q", url, code, body.Error)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST 状态码 = %d", rec.Code)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	s := newTestServer(t)
+
+	var health HealthResponse
+	if code := get(t, s, "/health", &health); code != http.StatusServiceUnavailable || health.Status != "starting" {
+		t.Errorf("启动时: %d %+v", code, health)
+	}
+
+	at := time.Date(2025, 2, 5, 2, 0, 0, 0, time.UTC)
+	s.RecordFetch(at, errors.New("连接超时"))
+	health = HealthResponse{}
+	if code := get(t, s, "/health", &health); code != http.StatusServiceUnavailable || health.Status != "error" || health.Error != "连接超时" {
+		t.Errorf("获取失败后: %d %+v", code, health)
+	}
+
+	s.RecordFetch(at, nil)
+	health = HealthResponse{}
+	if code := get(t, s, "/health", &health); code != http.StatusOK || health.Status != "ok" || !health.LastFetch.Equal(at) {
+		t.Errorf("获取成功后: %d %+v", code, health)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	s := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/calendar.ics?importance=high", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("状态码 %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if n := strings.Count(body, "BEGIN:VEVENT"); n != 2 {
+		t.Errorf("应包含一个高重要性事件和一个重要事件, 实际 %d 个\n%s", n, body)
+	}
+	if !strings.Contains(body, "SUMMARY:美国 1月ADP就业人数(万)") || strings.Contains(body, "贸易帐") {
+		t.Errorf("按重要性过滤错误:\n%s", body)
+	}
+
+	for _, url := range []string{"/calendar.ics?days=0", "/calendar.ics?days=abc", "/calendar.ics?importance=urgent"} {
+		var resp errorBody
+		if code := get(t, s, url, &resp); code != http.StatusBadRequest {
+			t.Errorf("%s: 状态码 %d", url, code)
+		}
+	}
+}
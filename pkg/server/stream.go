@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/yourusername/fmcl/pkg/changes"
+	"github.com/yourusername/fmcl/pkg/logger"
+)
+
+// 每个订阅者最多缓存的消息数，超出时断开该订阅者，避免拖慢获取数据
+const streamBuffer = 64
+
+// 连接空闲时发送注释行的间隔，防止代理关闭连接
+var streamHeartbeat = 15 * time.Second
+
+// 推送给订阅者的一条消息
+type message struct {
+	id     uint64
+	change changes.Change
+}
+
+// Publish 将一次获取发现的变化推送给 /stream 的全部订阅者
+func (s *Server) Publish(list []changes.Change) {
+	if len(list) == 0 {
+		return
+	}
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	for _, change := range list {
+		s.nextID++
+		for sub := range s.subscribers {
+			select {
+			case sub <- message{id: s.nextID, change: change}:
+			default:
+				logger.Warn("推送订阅者处理过慢，已断开")
+				delete(s.subscribers, sub)
+				close(sub)
+			}
+		}
+	}
+}
+
+func (s *Server) subscribe() chan message {
+	sub := make(chan message, streamBuffer)
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan message]struct{})
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (s *Server) unsubscribe(sub chan message) {
+	s.streamMu.Lock()
+	defer s.streamMu.Unlock()
+	if _, ok := s.subscribers[sub]; ok {
+		delete(s.subscribers, sub)
+		close(sub)
+	}
+}
+
+// 以 Server-Sent Events 推送变化，事件名为变化类型，数据为 changes.Change 的JSON
+func (s *Server) stream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{"只支持 GET 请求"})
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, errorBody{"连接不支持推送"})
+		return
+	}
+
+	sub := s.subscribe()
+	defer s.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-sub:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(msg.change)
+			if err != nil {
+				logger.Error("编码推送消息失败", zap.Error(err))
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.id, msg.change.Kind, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
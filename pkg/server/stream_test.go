@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/changes"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// 读取一条以空行结束的 SSE 消息，跳过心跳注释
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("读取推送失败: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && len(fields) > 0:
+			return fields
+		case line == "" || strings.HasPrefix(line, ":"):
+		default:
+			name, value, _ := strings.Cut(line, ": ")
+			fields[name] = value
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	event := parser.CalendarEvent{Time: "21:15", Region: "美国", Indicator: "1月ADP就业人数(万)", Actual: "18.3"}
+	rate := parser.CentralBankRate{Bank: "美联储", RateName: "联邦基金利率", CurrentRate: "4.25%"}
+	s.Publish([]changes.Change{
+		{Kind: changes.KindReleased, Date: "2025-02-05", Event: &event, Field: "actual", New: "18.3"},
+		{Kind: changes.KindRateChanged, Date: "2025-02-05", Rate: &rate, Field: "current_rate", Old: "4.50%", New: "4.25%"},
+	})
+
+	reader := bufio.NewReader(resp.Body)
+	first := readEvent(t, reader)
+	if first["id"] != "1" || first["event"] != "released" {
+		t.Errorf("第一条推送: %+v", first)
+	}
+	var change changes.Change
+	if err := json.Unmarshal([]byte(first["data"]), &change); err != nil || change.Event == nil || change.Event.Actual != "18.3" {
+		t.Errorf("推送数据: %+v %v", change, err)
+	}
+	if second := readEvent(t, reader); second["id"] != "2" || second["event"] != "rate_changed" {
+		t.Errorf("第二条推送: %+v", second)
+	}
+}
+
+func TestStreamSlowSubscriber(t *testing.T) {
+	s := newTestServer(t)
+	sub := s.subscribe()
+
+	list := make([]changes.Change, streamBuffer+1)
+	for i := range list {
+		list[i] = changes.Change{Kind: changes.KindReleased}
+	}
+	s.Publish(list)
+
+	received := 0
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-sub:
+			if !ok {
+				if received != streamBuffer {
+					t.Errorf("断开前收到 %d 条, 期望 %d", received, streamBuffer)
+				}
+				if len(s.subscribers) != 0 {
+					t.Error("处理过慢的订阅者应被移除")
+				}
+				return
+			}
+			received++
+		case <-timeout:
+			t.Fatal("处理过慢的订阅者未被断开")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package source
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// FX678BaseURL 汇通财经日历的默认地址
+const FX678BaseURL = "https://rl.fx678.com"
+
+// 汇通财经页面使用北京时间
+var fx678Location = mustLoadLocation("Asia/Shanghai")
+
+func init() {
+	Register("fx678", func(fetcher htmlfetcher.Fetcher) Source {
+		return NewFX678(fetcher)
+	})
+}
+
+// FX678 从汇通财经日历页面抓取数据
+type FX678 struct {
+	Fetcher htmlfetcher.Fetcher
+	BaseURL string
+}
+
+// NewFX678 返回使用默认地址的汇通财经数据源
+func NewFX678(fetcher htmlfetcher.Fetcher) *FX678 {
+	return &FX678{
+		Fetcher: fetcher,
+		BaseURL: FX678BaseURL,
+	}
+}
+
+// Name 返回数据源名称
+func (s *FX678) Name() string {
+	return "fx678"
+}
+
+// Location 返回页面使用的时区
+func (s *FX678) Location() *time.Location {
+	return fx678Location
+}
+
+// URL 返回指定日期的日历页面地址
+func (s *FX678) URL(date time.Time) string {
+	return fmt.Sprintf("%s/date/%s.html", s.BaseURL, date.Format("20060102"))
+}
+
+// Fetch 并发获取日期范围内的日历页面并逐日解析
+func (s *FX678) Fetch(from, to time.Time) ([]Snapshot, error) {
+	dates := days(from, to, s.Location())
+	urls := make([]string, len(dates))
+	for i, day := range dates {
+		urls[i] = s.URL(day)
+	}
+
+	pages, err := htmlfetcher.FetchAll(s.Fetcher, urls)
+	if err != nil {
+		return nil, fmt.Errorf("获取数据失败: %v", err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(dates))
+	for i, day := range dates {
+		result, err := parser.Parse(pages[i])
+		if err != nil {
+			return nil, fmt.Errorf("解析数据失败: %v", err)
+		}
+		result.SetDate(day)
+
+		snapshots = append(snapshots, Snapshot{
+			Source:          s.Name(),
+			Date:            day,
+			Events:          result.Events,
+			ImportantEvents: result.ImportantEvents,
+			Rates:           result.Rates,
+			Warnings:        result.Warnings,
+		})
+	}
+	return snapshots, nil
+}
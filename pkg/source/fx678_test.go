@@ -0,0 +1,147 @@
+package source
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/htmlfetcher/htmlfetchertest"
+)
+
+func newTestFX678(t *testing.T) (*FX678, *htmlfetchertest.Server) {
+	t.Helper()
+	server := htmlfetchertest.NewServer(filepath.Join("..", "..", "testdata", "fx678"))
+	t.Cleanup(server.Close)
+
+	src := NewFX678(&htmlfetcher.DefaultFetcher{})
+	src.BaseURL = server.URL
+	return src, server
+}
+
+func TestFX678FetchRange(t *testing.T) {
+	src, server := newTestFX678(t)
+
+	from := time.Date(2025, 2, 7, 12, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 8, 12, 0, 0, 0, time.UTC)
+	snapshots, err := src.Fetch(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 各日页面并发获取，请求顺序不固定
+	wantPaths := []string{"/date/20250207.html", "/date/20250208.html"}
+	got := server.Requests()
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, wantPaths) {
+		t.Errorf("请求路径 = %v, 期望 %v", got, wantPaths)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("快照数 = %d, 期望 2", len(snapshots))
+	}
+	if got := snapshots[0].Date.Format("2006-01-02"); got != "2025-02-07" {
+		t.Errorf("第一个快照日期 = %s", got)
+	}
+	if len(snapshots[0].Events) != 5 || len(snapshots[1].Events) != 0 {
+		t.Errorf("事件数 = %d/%d, 期望 5/0", len(snapshots[0].Events), len(snapshots[1].Events))
+	}
+	if snapshots[1].Source != "fx678" || len(snapshots[1].Rates) != 1 {
+		t.Errorf("周末页面解析错误: %+v", snapshots[1])
+	}
+}
+
+func TestFX678FetchWeekKeepsOrder(t *testing.T) {
+	src, server := newTestFX678(t)
+
+	monday := time.Date(2025, 2, 3, 12, 0, 0, 0, time.UTC)
+	snapshots, err := src.Fetch(monday, monday.AddDate(0, 0, 6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(server.Requests()) != 7 || len(snapshots) != 7 {
+		t.Fatalf("请求数/快照数 = %d/%d, 期望 7/7", len(server.Requests()), len(snapshots))
+	}
+	for i, snapshot := range snapshots {
+		want := fmt.Sprintf("2025-02-%02d", 3+i)
+		if got := snapshot.Date.Format("2006-01-02"); got != want {
+			t.Errorf("第%d个快照日期 = %s, 期望 %s", i, got, want)
+		}
+	}
+	if len(snapshots[2].Events) == 0 || len(snapshots[4].Events) != 5 {
+		t.Errorf("周三/周五事件数 = %d/%d", len(snapshots[2].Events), len(snapshots[4].Events))
+	}
+}
+
+// 总是失败的数据源
+type failingSource struct{}
+
+func (failingSource) Name() string { return "failing" }
+
+func (failingSource) Location() *time.Location { return time.UTC }
+
+func (failingSource) Fetch(from, to time.Time) ([]Snapshot, error) {
+	return nil, fmt.Errorf("不可用")
+}
+
+func TestFallbackUsesNextSource(t *testing.T) {
+	src, _ := newTestFX678(t)
+	fallback := &Fallback{Sources: []Source{failingSource{}, src}}
+
+	day := time.Date(2025, 2, 5, 12, 0, 0, 0, time.UTC)
+	snapshots, err := fallback.Fetch(day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Source != "fx678" {
+		t.Fatalf("应回退到 fx678: %+v", snapshots)
+	}
+
+	if _, err := (&Fallback{Sources: []Source{failingSource{}}}).Fetch(day, day); err == nil {
+		t.Error("所有数据源失败时应返回错误")
+	}
+}
+
+func TestNewFromConfig(t *testing.T) {
+	if _, err := NewFromConfig([]string{"nope"}, &htmlfetcher.DefaultFetcher{}); err == nil {
+		t.Error("未知数据源应返回错误")
+	}
+	src, err := NewFromConfig([]string{"fx678"}, &htmlfetcher.DefaultFetcher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Name() != "fx678" {
+		t.Errorf("数据源名称 = %s", src.Name())
+	}
+}
+
+func TestFX678UsesShanghaiTime(t *testing.T) {
+	src, server := newTestFX678(t)
+
+	// 伦敦时间 2月4日 22:00 已是北京时间 2月5日 06:00
+	london := time.FixedZone("GMT", 0)
+	now := time.Date(2025, 2, 4, 22, 0, 0, 0, london)
+	snapshots, err := src.Fetch(now, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := server.Requests(); len(got) != 1 || got[0] != "/date/20250205.html" {
+		t.Fatalf("应按北京时间选择页面: %v", got)
+	}
+
+	adp := snapshots[0].Events[3]
+	want := time.Date(2025, 2, 5, 13, 15, 0, 0, time.UTC)
+	if !adp.At.Equal(want) {
+		t.Errorf("ADP 公布时间 = %v, 期望 %v", adp.At, want)
+	}
+	if name, _ := adp.At.Zone(); name != "CST" {
+		t.Errorf("事件时间应使用数据源时区，得到 %s", name)
+	}
+
+	undecided := snapshots[0].ImportantEvents[1]
+	if undecided.Time != "待定" || !undecided.At.IsZero() {
+		t.Errorf("待定事件不应有时间: %+v", undecided)
+	}
+}
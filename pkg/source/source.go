@@ -0,0 +1,142 @@
+package source
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/yourusername/fmcl/pkg/htmlfetcher"
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// Snapshot 表示某个数据源某一天的标准化数据
+type Snapshot struct {
+	Source          string
+	Date            time.Time
+	Events          []parser.CalendarEvent
+	ImportantEvents []parser.ImportantEvent
+	Rates           []parser.CentralBankRate
+	Warnings        []parser.Warning
+}
+
+// LayoutChanged 判断数据源页面结构是否发生了变化
+func (s Snapshot) LayoutChanged() bool {
+	return parser.LayoutChanged(s.Warnings)
+}
+
+// Source 定义了财经日历数据源的接口
+type Source interface {
+	// Name 返回数据源名称
+	Name() string
+	// Location 返回数据源页面使用的时区
+	Location() *time.Location
+	// Fetch 获取 from 到 to（含）之间每一天的数据，日期按数据源时区计算
+	Fetch(from, to time.Time) ([]Snapshot, error)
+}
+
+// Factory 根据HTML获取器创建数据源
+type Factory func(fetcher htmlfetcher.Fetcher) Source
+
+var registry = make(map[string]Factory)
+
+// Register 注册一个数据源，重复注册会覆盖之前的实现
+func Register(name string, factory Factory) {
+	registry[name] = factory
+}
+
+// Names 返回已注册的数据源名称
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New 按名称创建数据源
+func New(name string, fetcher htmlfetcher.Fetcher) (Source, error) {
+	factory, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("未知的数据源: %s (可用: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(fetcher), nil
+}
+
+// NewFromConfig 按配置顺序创建数据源，前一个失败时依次尝试后一个
+func NewFromConfig(names []string, fetcher htmlfetcher.Fetcher) (Source, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("未配置数据源")
+	}
+
+	var sources []Source
+	for _, name := range names {
+		src, err := New(name, fetcher)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) == 1 {
+		return sources[0], nil
+	}
+	return &Fallback{Sources: sources}, nil
+}
+
+// Fallback 按顺序尝试多个数据源，返回第一个成功的结果
+type Fallback struct {
+	Sources []Source
+}
+
+// Name 返回组合后的数据源名称
+func (f *Fallback) Name() string {
+	names := make([]string, 0, len(f.Sources))
+	for _, src := range f.Sources {
+		names = append(names, src.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+// Location 返回第一个数据源的时区
+func (f *Fallback) Location() *time.Location {
+	return f.Sources[0].Location()
+}
+
+// Fetch 依次尝试各数据源
+func (f *Fallback) Fetch(from, to time.Time) ([]Snapshot, error) {
+	var errs []string
+	for _, src := range f.Sources {
+		snapshots, err := src.Fetch(from, to)
+		if err == nil {
+			return snapshots, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", src.Name(), err))
+	}
+	return nil, fmt.Errorf("所有数据源均获取失败: %s", strings.Join(errs, "; "))
+}
+
+// DateLayouts 是命令行、终端界面和 HTTP 接口接受的日期格式
+var DateLayouts = []string{"20060102", "2006-01-02", "2006/01/02", "2006.01.02"}
+
+// 返回 from 到 to（含）之间在 loc 时区下的每一天
+func days(from, to time.Time, loc *time.Location) []time.Time {
+	var result []time.Time
+	from, to = from.In(loc), to.In(loc)
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for !day.After(to) {
+		result = append(result, day)
+		day = day.AddDate(0, 0, 1)
+	}
+	return result
+}
+
+// 加载时区。程序内置了时区数据库，加载失败只可能是时区名称写错
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(fmt.Sprintf("加载时区 %s 失败: %v", name, err))
+	}
+	return loc
+}
@@ -0,0 +1,267 @@
+package storage
+
+import (
+	"database/sql"
+	"regexp"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/surprise"
+)
+
+// 计算Z分数时最多使用的历史样本数
+const surpriseHistoryLimit = 60
+
+// StoredEvent 表示数据库中的财经日历事件
+type StoredEvent struct {
+	Date string `json:"date"`
+	parser.CalendarEvent
+	Surprise *surprise.Surprise `json:"surprise"` // 公布值或预测值缺失时为 nil
+}
+
+// CalendarEvents 返回指定日期的财经日历事件，按时间排序，并附带意外值
+func (db *DB) CalendarEvents(date string) ([]StoredEvent, error) {
+	rows, err := db.Conn.Query(`
+		SELECT date, time, region, indicator, previous, forecast, actual, importance, impact, description, at
+		FROM calendar_events WHERE date = ? ORDER BY time, id
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	events, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range events {
+		s, err := db.ScoreSurprise(events[i].Date, events[i].CalendarEvent)
+		if err != nil {
+			return nil, err
+		}
+		events[i].Surprise = s
+	}
+	return events, nil
+}
+
+// 读取事件行并解析数值
+func scanEvents(rows *sql.Rows) ([]StoredEvent, error) {
+	defer rows.Close()
+
+	var events []StoredEvent
+	for rows.Next() {
+		var e StoredEvent
+		var previous, forecast, actual, importance, impact, description sql.NullString
+		var at sql.NullTime
+		if err := rows.Scan(&e.Date, &e.Time, &e.Region, &e.Indicator,
+			&previous, &forecast, &actual, &importance, &impact, &description, &at); err != nil {
+			return nil, err
+		}
+		e.At = at.Time
+		e.Previous = previous.String
+		e.Forecast = forecast.String
+		e.Actual = actual.String
+		e.Importance = importance.String
+		e.Impact = impact.String
+		e.Description = description.String
+		e.ParseValues()
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// SurpriseHistory 返回某地区某指标在 date 之前的历史意外值（已换算单位），从新到旧
+func (db *DB) SurpriseHistory(region, series, date string) ([]float64, error) {
+	rows, err := db.Conn.Query(`
+		SELECT surprise FROM calendar_events
+		WHERE region = ? AND series = ? AND date < ? AND surprise IS NOT NULL
+		ORDER BY date DESC, time DESC LIMIT ?
+	`, region, series, date, surpriseHistoryLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []float64
+	for rows.Next() {
+		var value float64
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		history = append(history, value)
+	}
+	return history, rows.Err()
+}
+
+// ScoreSurprise 计算事件的意外值，并以该指标的历史意外计算Z分数；
+// 无法计算意外时返回 nil
+func (db *DB) ScoreSurprise(date string, e parser.CalendarEvent) (*surprise.Surprise, error) {
+	s, ok := surprise.Compute(e)
+	if !ok {
+		return nil, nil
+	}
+
+	history, err := db.SurpriseHistory(e.Region, parser.SeriesName(e.Indicator), date)
+	if err != nil {
+		return nil, err
+	}
+	s = s.WithHistory(history)
+	return &s, nil
+}
+
+// HistoryQuery 历史查询条件，为空的条件不参与过滤
+type HistoryQuery struct {
+	Indicator *regexp.Regexp // 指标名称的正则表达式
+	Series    string         // 去掉统计期的指标名称，见 parser.SeriesName
+	Region    string
+	Since     string // 起始日期 YYYY-MM-DD（含）
+	Until     string // 截止日期 YYYY-MM-DD（含）
+	Limit     int    // 最多返回最近的多少条，0 表示不限
+}
+
+// History 返回符合条件的财经日历事件，从新到旧，并附带意外值
+func (db *DB) History(q HistoryQuery) ([]StoredEvent, error) {
+	query := `
+		SELECT date, time, region, indicator, previous, forecast, actual, importance, impact, description, at
+		FROM calendar_events WHERE 1 = 1`
+	var args []interface{}
+	for _, cond := range []struct {
+		clause string
+		value  string
+	}{
+		{" AND series = ?", q.Series},
+		{" AND region = ?", q.Region},
+		{" AND date >= ?", q.Since},
+		{" AND date <= ?", q.Until},
+	} {
+		if cond.value != "" {
+			query += cond.clause
+			args = append(args, cond.value)
+		}
+	}
+	query += " ORDER BY date DESC, time DESC, id DESC"
+
+	rows, err := db.Conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := scanEvents(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite 没有内置正则，指标在读取后过滤
+	var events []StoredEvent
+	for _, e := range stored {
+		if q.Indicator != nil && !q.Indicator.MatchString(e.Indicator) {
+			continue
+		}
+		if q.Limit > 0 && len(events) == q.Limit {
+			break
+		}
+		s, err := db.ScoreSurprise(e.Date, e.CalendarEvent)
+		if err != nil {
+			return nil, err
+		}
+		e.Surprise = s
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// HasDate 判断数据库中是否保存过指定日期的任何数据
+func (db *DB) HasDate(date string) (bool, error) {
+	var found bool
+	err := db.Conn.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM calendar_events WHERE date = ?)
+			OR EXISTS (SELECT 1 FROM important_events WHERE date = ?)
+			OR EXISTS (SELECT 1 FROM central_bank_rates WHERE date = ?)
+	`, date, date, date).Scan(&found)
+	return found, err
+}
+
+// UpdatedAt 返回指定日期的数据最后一次保存的时间，没有数据时返回零值
+func (db *DB) UpdatedAt(date string) (time.Time, error) {
+	var latest time.Time
+	for _, table := range []string{"calendar_events", "important_events", "central_bank_rates"} {
+		rows, err := db.Conn.Query("SELECT updated_at FROM "+table+" WHERE date = ? AND updated_at IS NOT NULL", date)
+		if err != nil {
+			return time.Time{}, err
+		}
+		for rows.Next() {
+			var updatedAt time.Time
+			if err := rows.Scan(&updatedAt); err != nil {
+				rows.Close()
+				return time.Time{}, err
+			}
+			if updatedAt.After(latest) {
+				latest = updatedAt
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return latest, nil
+}
+
+// ImportantEvents 返回指定日期的重要事件，按时间排序
+func (db *DB) ImportantEvents(date string) ([]parser.ImportantEvent, error) {
+	rows, err := db.Conn.Query(`
+		SELECT time, region, location, importance, event, at
+		FROM important_events WHERE date = ? ORDER BY time, id
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []parser.ImportantEvent
+	for rows.Next() {
+		var e parser.ImportantEvent
+		var location, importance sql.NullString
+		var at sql.NullTime
+		if err := rows.Scan(&e.Time, &e.Region, &location, &importance, &e.Event, &at); err != nil {
+			return nil, err
+		}
+		e.Location = location.String
+		e.Importance = importance.String
+		e.At = at.Time
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// CentralBankRates 返回指定日期记录的央行利率
+func (db *DB) CentralBankRates(date string) ([]parser.CentralBankRate, error) {
+	rows, err := db.Conn.Query(`
+		SELECT bank, rate_name, current_rate, previous_rate, last_change,
+			history_high, history_low, next_forecast, latest_cpi, updated_at
+		FROM central_bank_rates WHERE date = ? ORDER BY id
+	`, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []parser.CentralBankRate
+	for rows.Next() {
+		var r parser.CentralBankRate
+		var fields [7]sql.NullString
+		var updatedAt sql.NullTime
+		if err := rows.Scan(&r.Bank, &r.RateName, &fields[0], &fields[1], &fields[2],
+			&fields[3], &fields[4], &fields[5], &fields[6], &updatedAt); err != nil {
+			return nil, err
+		}
+		r.CurrentRate = fields[0].String
+		r.PreviousRate = fields[1].String
+		r.LastChange = fields[2].String
+		r.HistoryHigh = fields[3].String
+		r.HistoryLow = fields[4].String
+		r.NextForecast = fields[5].String
+		r.LatestCPI = fields[6].String
+		r.LastUpdateTime = updatedAt.Time
+		rates = append(rates, r)
+	}
+	return rates, rows.Err()
+}
@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/surprise"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration 表示一个版本化的数据库迁移
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus 表示迁移在当前数据库中的应用状态
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrations 返回按版本号排序的全部内置迁移
+func Migrations() ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		name := entry.Name()
+		// 文件名格式: 0001_描述.sql
+		prefix, _, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("迁移文件名格式错误: %s", name)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("迁移文件版本号错误: %s", name)
+		}
+		data, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    strings.TrimSuffix(name, ".sql"),
+			SQL:     string(data),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("迁移版本号重复: %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// 创建版本记录表
+func (db *DB) ensureVersionTable() error {
+	_, err := db.Conn.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`)
+	return err
+}
+
+// 读取已应用的迁移
+func (db *DB) appliedMigrations() (map[int]time.Time, error) {
+	if err := db.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Conn.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// SchemaVersion 返回数据库当前的结构版本，未执行过迁移时为0
+func (db *DB) SchemaVersion() (int, error) {
+	if err := db.ensureVersionTable(); err != nil {
+		return 0, err
+	}
+
+	var version sql.NullInt64
+	if err := db.Conn.QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return int(version.Int64), nil
+}
+
+// Migrate 按版本顺序执行尚未应用的迁移，只能向前升级
+func (db *DB) Migrate() ([]Migration, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := db.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) > 0 && current > migrations[len(migrations)-1].Version {
+		return nil, fmt.Errorf("数据库结构版本 %d 高于程序支持的版本 %d，请升级程序",
+			current, migrations[len(migrations)-1].Version)
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := db.apply(m); err != nil {
+			return applied, fmt.Errorf("执行迁移 %s 失败: %v", m.Name, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// 新增的列需要用 Go 代码从已有数据计算时，在迁移的同一事务中回填
+var backfills = map[int]func(tx *sql.Tx) error{
+	4: backfillSurprise,
+}
+
+// 为迁移 0004 之前保存的事件计算指标序列名和意外值
+func backfillSurprise(tx *sql.Tx) error {
+	rows, err := tx.Query(`
+		SELECT id, indicator, forecast, actual, impact FROM calendar_events WHERE series IS NULL
+	`)
+	if err != nil {
+		return err
+	}
+
+	type backfilled struct {
+		id       int64
+		series   string
+		surprise sql.NullFloat64
+	}
+	var updates []backfilled
+	for rows.Next() {
+		var id int64
+		var e parser.CalendarEvent
+		var forecast, actual, impact sql.NullString
+		if err := rows.Scan(&id, &e.Indicator, &forecast, &actual, &impact); err != nil {
+			rows.Close()
+			return err
+		}
+		e.Forecast, e.Actual, e.Impact = forecast.String, actual.String, impact.String
+		e.ParseValues()
+
+		u := backfilled{id: id, series: parser.SeriesName(e.Indicator)}
+		if s, ok := surprise.Compute(e); ok {
+			u.surprise = sql.NullFloat64{Float64: s.Scaled(), Valid: true}
+		}
+		updates = append(updates, u)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, u := range updates {
+		if _, err := tx.Exec("UPDATE calendar_events SET series = ?, surprise = ? WHERE id = ?",
+			u.series, u.surprise, u.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 在单个事务中执行一个迁移并记录版本
+func (db *DB) apply(m Migration) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return err
+	}
+	if backfill, ok := backfills[m.Version]; ok {
+		if err := backfill(tx); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+		m.Version, m.Name, time.Now(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MigrationStatus 返回全部内置迁移及其应用状态
+func (db *DB) MigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		status = append(status, MigrationStatus{
+			Migration: m,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return status, nil
+}
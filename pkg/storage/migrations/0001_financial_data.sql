@@ -0,0 +1,8 @@
+-- 原始财经数据表
+CREATE TABLE IF NOT EXISTS financial_data (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	previous_value TEXT,
+	forecast_value TEXT,
+	actual_value TEXT,
+	timestamp DATETIME
+);
@@ -0,0 +1,44 @@
+-- 财经日历事件、重要事件与央行利率
+CREATE TABLE IF NOT EXISTS calendar_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date TEXT NOT NULL,
+	time TEXT NOT NULL,
+	region TEXT NOT NULL,
+	indicator TEXT NOT NULL,
+	previous TEXT,
+	forecast TEXT,
+	actual TEXT,
+	importance TEXT,
+	impact TEXT,
+	description TEXT,
+	updated_at DATETIME,
+	UNIQUE (date, time, region, indicator)
+);
+
+CREATE TABLE IF NOT EXISTS important_events (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date TEXT NOT NULL,
+	time TEXT NOT NULL,
+	region TEXT NOT NULL,
+	event TEXT NOT NULL,
+	location TEXT,
+	importance TEXT,
+	updated_at DATETIME,
+	UNIQUE (date, time, region, event)
+);
+
+CREATE TABLE IF NOT EXISTS central_bank_rates (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date TEXT NOT NULL,
+	bank TEXT NOT NULL,
+	rate_name TEXT NOT NULL,
+	current_rate TEXT,
+	previous_rate TEXT,
+	last_change TEXT,
+	history_high TEXT,
+	history_low TEXT,
+	next_forecast TEXT,
+	latest_cpi TEXT,
+	updated_at DATETIME,
+	UNIQUE (date, bank, rate_name)
+);
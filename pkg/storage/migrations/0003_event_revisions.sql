@@ -0,0 +1,15 @@
+-- 财经日历事件前值/公布值的修正记录
+CREATE TABLE IF NOT EXISTS event_revisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	date TEXT NOT NULL,
+	time TEXT NOT NULL,
+	region TEXT NOT NULL,
+	indicator TEXT NOT NULL,
+	field TEXT NOT NULL,
+	old_value TEXT,
+	new_value TEXT,
+	observed_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_revisions_event
+	ON event_revisions (date, time, region, indicator);
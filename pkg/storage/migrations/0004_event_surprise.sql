@@ -0,0 +1,6 @@
+-- 指标序列名与意外值（公布值 - 预测值，已换算单位）
+ALTER TABLE calendar_events ADD COLUMN series TEXT;
+ALTER TABLE calendar_events ADD COLUMN surprise REAL;
+
+CREATE INDEX IF NOT EXISTS idx_calendar_events_series
+	ON calendar_events (region, series, date);
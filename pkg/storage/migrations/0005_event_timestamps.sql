@@ -0,0 +1,3 @@
+-- 事件的完整时间（含日期与时区）
+ALTER TABLE calendar_events ADD COLUMN at DATETIME;
+ALTER TABLE important_events ADD COLUMN at DATETIME;
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// 需要跟踪修正的字段
+const (
+	FieldPrevious = "previous"
+	FieldActual   = "actual"
+)
+
+// Revision 表示一次观察到的字段变化
+type Revision struct {
+	Date       string
+	Time       string
+	Region     string
+	Indicator  string
+	Field      string
+	OldValue   string
+	NewValue   string
+	ObservedAt time.Time
+}
+
+// Published 判断该变化是否为首次公布（旧值为空），而非对已有数值的修正
+func (r Revision) Published() bool {
+	return r.OldValue == ""
+}
+
+// 对比已存储的快照，返回本次事件中变化的字段
+func diffStoredEvent(tx *sql.Tx, date string, e parser.CalendarEvent, now time.Time) ([]Revision, error) {
+	var previous, actual sql.NullString
+	err := tx.QueryRow(
+		"SELECT previous, actual FROM calendar_events WHERE date = ? AND time = ? AND region = ? AND indicator = ?",
+		date, e.Time, e.Region, e.Indicator,
+	).Scan(&previous, &actual)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []Revision
+	// 新值为空时（例如页面暂时缺失该字段）不记录
+	add := func(field, oldValue, newValue string) {
+		if oldValue == newValue || newValue == "" {
+			return
+		}
+		revisions = append(revisions, Revision{
+			Date:       date,
+			Time:       e.Time,
+			Region:     e.Region,
+			Indicator:  e.Indicator,
+			Field:      field,
+			OldValue:   oldValue,
+			NewValue:   newValue,
+			ObservedAt: now,
+		})
+	}
+	add(FieldPrevious, previous.String, e.Previous)
+	add(FieldActual, actual.String, e.Actual)
+	return revisions, nil
+}
+
+// 写入修正记录
+func insertRevisions(tx *sql.Tx, revisions []Revision) error {
+	for _, r := range revisions {
+		if _, err := tx.Exec(`
+			INSERT INTO event_revisions
+				(date, time, region, indicator, field, old_value, new_value, observed_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, r.Date, r.Time, r.Region, r.Indicator, r.Field, r.OldValue, r.NewValue, r.ObservedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revisions 返回指定日期的全部修正记录，按观察时间排序
+func (db *DB) Revisions(date string) ([]Revision, error) {
+	return db.queryRevisions(`
+		SELECT date, time, region, indicator, field, old_value, new_value, observed_at
+		FROM event_revisions WHERE date = ? ORDER BY observed_at, id
+	`, date)
+}
+
+// EventRevisions 返回单个财经日历事件的修正记录，按观察时间排序
+func (db *DB) EventRevisions(date, eventTime, region, indicator string) ([]Revision, error) {
+	return db.queryRevisions(`
+		SELECT date, time, region, indicator, field, old_value, new_value, observed_at
+		FROM event_revisions
+		WHERE date = ? AND time = ? AND region = ? AND indicator = ?
+		ORDER BY observed_at, id
+	`, date, eventTime, region, indicator)
+}
+
+func (db *DB) queryRevisions(query string, args ...interface{}) ([]Revision, error) {
+	rows, err := db.Conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []Revision
+	for rows.Next() {
+		var r Revision
+		var oldValue, newValue sql.NullString
+		if err := rows.Scan(&r.Date, &r.Time, &r.Region, &r.Indicator, &r.Field,
+			&oldValue, &newValue, &r.ObservedAt); err != nil {
+			return nil, err
+		}
+		r.OldValue = oldValue.String
+		r.NewValue = newValue.String
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
@@ -5,38 +5,50 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 	"os"
 	"path/filepath"
+	"time"
+
 	"github.com/yourusername/fmcl/pkg/parser"
+	"github.com/yourusername/fmcl/pkg/surprise"
 )
 
 type DB struct {
 	Conn *sql.DB
 }
 
+// NewDB 打开数据库并执行所有未应用的迁移
 func NewDB(path string) (*DB, error) {
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+	db, err := Open(path)
+	if err != nil {
 		return nil, err
 	}
 
-	conn, err := sql.Open("sqlite3", path)
-	if err != nil {
+	if _, err := db.Migrate(); err != nil {
+		db.Close()
 		return nil, err
 	}
 
-	if _, err := conn.Exec(`
-		CREATE TABLE IF NOT EXISTS financial_data (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			previous_value TEXT,
-			forecast_value TEXT,
-			actual_value TEXT,
-			timestamp DATETIME
-		)
-	`); err != nil {
+	return db, nil
+}
+
+// Open 打开数据库但不执行迁移
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
 		return nil, err
 	}
 
 	return &DB{Conn: conn}, nil
 }
 
+// Close 关闭数据库连接
+func (db *DB) Close() error {
+	return db.Conn.Close()
+}
+
 func (db *DB) Save(data *parser.FinancialData) error {
 	_, err := db.Conn.Exec(
 		"INSERT INTO financial_data (previous_value, forecast_value, actual_value, timestamp) VALUES (?, ?, ?, ?)",
@@ -44,3 +56,138 @@ func (db *DB) Save(data *parser.FinancialData) error {
 	)
 	return err
 }
+
+// SaveCalendarEvents 按 日期+时间+地区+指标 写入或更新财经日历事件，
+// 并与上次存储的快照对比，记录前值和公布值的变化。
+// 新的前值或公布值为空时（例如页面暂时缺失）保留已存储的值和意外值
+func (db *DB) SaveCalendarEvents(date string, events []parser.CalendarEvent) ([]Revision, error) {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO calendar_events
+			(date, time, region, indicator, previous, forecast, actual, importance, impact, description,
+			 series, surprise, at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (date, time, region, indicator) DO UPDATE SET
+			previous = COALESCE(NULLIF(excluded.previous, ''), previous),
+			forecast = excluded.forecast,
+			actual = COALESCE(NULLIF(excluded.actual, ''), actual),
+			importance = excluded.importance,
+			impact = excluded.impact,
+			description = excluded.description,
+			series = excluded.series,
+			surprise = CASE WHEN excluded.actual = '' THEN surprise ELSE excluded.surprise END,
+			at = excluded.at,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	var revisions []Revision
+	for _, e := range events {
+		changed, err := diffStoredEvent(tx, date, e, now)
+		if err != nil {
+			return nil, err
+		}
+		if err := insertRevisions(tx, changed); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, changed...)
+
+		var surpriseValue sql.NullFloat64
+		if s, ok := surprise.Compute(e); ok {
+			surpriseValue = sql.NullFloat64{Float64: s.Scaled(), Valid: true}
+		}
+		if _, err := stmt.Exec(date, e.Time, e.Region, e.Indicator,
+			e.Previous, e.Forecast, e.Actual, e.Importance, e.Impact, e.Description,
+			parser.SeriesName(e.Indicator), surpriseValue, nullTime(e.At), now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// SaveImportantEvents 按 日期+时间+地区+事件 写入或更新重要事件
+func (db *DB) SaveImportantEvents(date string, events []parser.ImportantEvent) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO important_events
+			(date, time, region, event, location, importance, at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (date, time, region, event) DO UPDATE SET
+			location = excluded.location,
+			importance = excluded.importance,
+			at = excluded.at,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, e := range events {
+		if _, err := stmt.Exec(date, e.Time, e.Region, e.Event, e.Location, e.Importance, nullTime(e.At), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SaveCentralBankRates 按 日期+央行+利率名称 写入或更新央行利率
+func (db *DB) SaveCentralBankRates(date string, rates []parser.CentralBankRate) error {
+	tx, err := db.Conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO central_bank_rates
+			(date, bank, rate_name, current_rate, previous_rate, last_change,
+			 history_high, history_low, next_forecast, latest_cpi, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (date, bank, rate_name) DO UPDATE SET
+			current_rate = excluded.current_rate,
+			previous_rate = excluded.previous_rate,
+			last_change = excluded.last_change,
+			history_high = excluded.history_high,
+			history_low = excluded.history_low,
+			next_forecast = excluded.next_forecast,
+			latest_cpi = excluded.latest_cpi,
+			updated_at = excluded.updated_at
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now()
+	for _, r := range rates {
+		if _, err := stmt.Exec(date, r.Bank, r.RateName, r.CurrentRate, r.PreviousRate, r.LastChange,
+			r.HistoryHigh, r.HistoryLow, r.NextForecast, r.LatestCPI, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// 零值时间存为 NULL
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := NewDB(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func calendarEvent(indicator, previous, forecast, actual string) parser.CalendarEvent {
+	e := parser.CalendarEvent{
+		Time:       "21:30",
+		Region:     "美国",
+		Indicator:  indicator,
+		Previous:   previous,
+		Forecast:   forecast,
+		Actual:     actual,
+		Importance: "高",
+		Impact:     "利多 美元",
+	}
+	e.ParseValues()
+	return e
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db := newTestDB(t)
+
+	applied, err := db.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("重复执行不应再应用迁移: %v", applied)
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != migrations[len(migrations)-1].Version {
+		t.Errorf("结构版本 = %d, 期望 %d", version, migrations[len(migrations)-1].Version)
+	}
+}
+
+func TestMigrateBackfillsSurprise(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "fmt.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// 模拟迁移 0004 之前保存的数据
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.ensureVersionTable(); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range migrations {
+		if m.Version < 4 {
+			if err := db.apply(m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for date, actual := range map[string]string{"2024-11-01": "16", "2024-12-06": "17", "2025-01-10": "18"} {
+		if _, err := db.Conn.Exec(`
+			INSERT INTO calendar_events (date, time, region, indicator, forecast, actual, impact)
+			VALUES (?, '21:30', '美国', ?, '15', ?, '利多 美元')
+		`, date, date[5:7]+"月季调后非农就业人口(万)", actual); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := db.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	history, err := db.SurpriseHistory("美国", "季调后非农就业人口(万)", "2025-02-07")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 3 {
+		t.Fatalf("迁移后应回填已有事件的意外值: %v", history)
+	}
+	events, err := db.History(HistoryQuery{Series: "季调后非农就业人口(万)", Region: "美国"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Errorf("迁移后应回填已有事件的指标序列名: %d", len(events))
+	}
+}
+
+func TestSaveCalendarEventsRecordsRevisions(t *testing.T) {
+	db := newTestDB(t)
+	date := "2025-02-07"
+
+	if _, err := db.SaveCalendarEvents(date, []parser.CalendarEvent{calendarEvent("1月失业率", "4.1%", "4.1%", "")}); err != nil {
+		t.Fatal(err)
+	}
+	revisions, err := db.SaveCalendarEvents(date, []parser.CalendarEvent{calendarEvent("1月失业率", "4.2%", "4.1%", "4.0%")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("应记录前值修正和公布值公布: %+v", revisions)
+	}
+
+	stored, err := db.EventRevisions(date, "21:30", "美国", "1月失业率")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || stored[0].Field != FieldPrevious || stored[0].OldValue != "4.1%" || stored[0].Published() {
+		t.Errorf("前值修正记录错误: %+v", stored)
+	}
+	if stored[1].Field != FieldActual || !stored[1].Published() {
+		t.Errorf("公布值首次公布应记为 Published: %+v", stored[1])
+	}
+
+	events, err := db.CalendarEvents(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Previous != "4.2%" || events[0].Actual != "4.0%" {
+		t.Errorf("应只保留一条更新后的事件: %+v", events)
+	}
+
+	// 数值变为空不记为修正
+	revisions, err = db.SaveCalendarEvents(date, []parser.CalendarEvent{calendarEvent("1月失业率", "", "4.1%", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("数值变为空不应记录修正: %+v", revisions)
+	}
+	events, err = db.CalendarEvents(date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Previous != "4.2%" || events[0].Actual != "4.0%" || events[0].Surprise == nil {
+		t.Errorf("数值变为空时应保留已存储的值: %+v", events)
+	}
+
+	// 数值恢复后不应再记为一次公布
+	revisions, err = db.SaveCalendarEvents(date, []parser.CalendarEvent{calendarEvent("1月失业率", "4.2%", "4.1%", "4.0%")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 0 {
+		t.Errorf("数值恢复不应记录修正: %+v", revisions)
+	}
+	stored, err = db.EventRevisions(date, "21:30", "美国", "1月失业率")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 {
+		t.Errorf("修正记录应仍为2条: %+v", stored)
+	}
+}
+
+func TestSurpriseZScoreUsesHistory(t *testing.T) {
+	db := newTestDB(t)
+
+	// 同一指标的历史意外: +1, +2, +3（万）
+	history := map[string]string{"2024-11-01": "16", "2024-12-06": "17", "2025-01-10": "18"}
+	months := map[string]string{"2024-11-01": "10月", "2024-12-06": "11月", "2025-01-10": "12月"}
+	for date, actual := range history {
+		e := calendarEvent(months[date]+"季调后非农就业人口(万)", "", "15", actual)
+		if _, err := db.SaveCalendarEvents(date, []parser.CalendarEvent{e}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := db.ScoreSurprise("2025-02-07", calendarEvent("1月季调后非农就业人口(万)", "", "15", "19"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s == nil || !s.HasZScore || s.ZScore != 2 {
+		t.Fatalf("意外值 +4 相对历史 {1,2,3} 的Z分数应为 2: %+v", s)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	db := newTestDB(t)
+
+	days := []struct{ date, period, actual string }{
+		{"2024-12-11", "11月", "2.7%"},
+		{"2025-01-15", "12月", "2.9%"},
+		{"2025-02-12", "1月", "3.0%"},
+	}
+	for _, d := range days {
+		events := []parser.CalendarEvent{
+			calendarEvent(d.period+"CPI年率", "", "2.8%", d.actual),
+			calendarEvent(d.period+"核心CPI年率", "", "3.2%", "3.3%"),
+		}
+		if _, err := db.SaveCalendarEvents(d.date, events); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := calendarEvent("1月CPI年率", "", "2.4%", "2.5%")
+	other.Region = "欧元区"
+	if _, err := db.SaveCalendarEvents("2025-02-12", []parser.CalendarEvent{other}); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := db.History(HistoryQuery{Indicator: regexp.MustCompile(`^\d+月CPI年率`), Region: "美国", Since: "2025-01-01"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Date != "2025-02-12" || events[1].Date != "2025-01-15" {
+		t.Fatalf("按正则、地区和日期过滤，从新到旧: %+v", events)
+	}
+	if events[0].Surprise == nil || events[0].Surprise.String() == "" {
+		t.Errorf("应附带意外值: %+v", events[0].Surprise)
+	}
+
+	events, err = db.History(HistoryQuery{Series: parser.SeriesName("1月CPI年率"), Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Indicator != "1月CPI年率" || events[1].Indicator != "1月CPI年率" {
+		t.Errorf("按序列名查询最近 2 条: %+v", events)
+	}
+}
@@ -0,0 +1,98 @@
+// Package surprise 计算财经数据公布值相对预测值的意外程度
+package surprise
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// Direction 表示数据对市场的影响方向，取自事件的利多利空字段
+type Direction string
+
+const (
+	DirectionNone    Direction = ""
+	DirectionBullish Direction = "利多"
+	DirectionBearish Direction = "利空"
+)
+
+// 计算Z分数所需的最少历史样本数
+const MinHistory = 3
+
+// Surprise 表示一次数据公布的意外
+type Surprise struct {
+	Value     float64     `json:"value"`       // 公布值 - 预测值，以公布值的单位表示
+	Unit      parser.Unit `json:"unit"`        // 单位
+	Direction Direction   `json:"direction"`   // 影响方向
+	ZScore    float64     `json:"z_score"`     // 相对该指标历史意外的标准化分数
+	HasZScore bool        `json:"has_z_score"` // 历史样本足够时才有Z分数
+}
+
+// Compute 计算事件的意外，公布值或预测值缺失、单位不可比较时返回 false
+func Compute(e parser.CalendarEvent) (Surprise, bool) {
+	actual, forecast := e.ActualValue, e.ForecastValue
+	if !actual.Comparable(forecast) {
+		return Surprise{}, false
+	}
+
+	return Surprise{
+		Value:     (actual.Scaled() - forecast.Scaled()) / actual.Unit.Scale(),
+		Unit:      actual.Unit,
+		Direction: ParseDirection(e.Impact),
+	}, true
+}
+
+// ParseDirection 从利多利空字段中读取影响方向
+func ParseDirection(impact string) Direction {
+	switch {
+	case strings.Contains(impact, string(DirectionBullish)):
+		return DirectionBullish
+	case strings.Contains(impact, string(DirectionBearish)):
+		return DirectionBearish
+	}
+	return DirectionNone
+}
+
+// Scaled 返回换算单位后的意外值，用于跨期比较
+func (s Surprise) Scaled() float64 {
+	return s.Value * s.Unit.Scale()
+}
+
+// WithHistory 根据该指标历史意外（已换算单位）计算Z分数
+func (s Surprise) WithHistory(history []float64) Surprise {
+	s.ZScore, s.HasZScore = ZScore(s.Scaled(), history)
+	return s
+}
+
+// ZScore 计算 value 相对历史样本的标准化分数，样本不足或没有波动时返回 false
+func ZScore(value float64, history []float64) (float64, bool) {
+	if len(history) < MinHistory {
+		return 0, false
+	}
+
+	mean := 0.0
+	for _, h := range history {
+		mean += h
+	}
+	mean /= float64(len(history))
+
+	variance := 0.0
+	for _, h := range history {
+		variance += (h - mean) * (h - mean)
+	}
+	std := math.Sqrt(variance / float64(len(history)-1))
+	if std == 0 {
+		return 0, false
+	}
+	return (value - mean) / std, true
+}
+
+func (s Surprise) String() string {
+	text := fmt.Sprintf("%+.4g%s", s.Value, s.Unit)
+	if s.HasZScore {
+		text += fmt.Sprintf(" (%+.1fσ)", s.ZScore)
+	}
+	return text
+}
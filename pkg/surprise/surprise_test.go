@@ -0,0 +1,61 @@
+package surprise
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+func event(forecast, actual, impact string) parser.CalendarEvent {
+	e := parser.CalendarEvent{Indicator: "1月失业率", Forecast: forecast, Actual: actual, Impact: impact}
+	e.ParseValues()
+	return e
+}
+
+func TestCompute(t *testing.T) {
+	s, ok := Compute(event("4.1%", "4.0%", "利多 美元"))
+	if !ok {
+		t.Fatal("应能计算意外值")
+	}
+	if math.Abs(s.Value-(-0.1)) > 1e-9 || s.Unit != parser.UnitPercent || s.Direction != DirectionBullish {
+		t.Errorf("意外值错误: %+v", s)
+	}
+
+	for _, e := range []parser.CalendarEvent{
+		event("4.1%", "", ""),
+		event("", "4.0%", ""),
+		event("4.1%", "4.0", ""),
+	} {
+		if _, ok := Compute(e); ok {
+			t.Errorf("缺失或不可比较时不应计算意外值: %+v", e)
+		}
+	}
+}
+
+func TestComputeConvertsUnits(t *testing.T) {
+	e := parser.CalendarEvent{Forecast: "170K", Actual: "14.3万", Impact: "利空 美元"}
+	e.ParseValues()
+
+	s, ok := Compute(e)
+	if !ok {
+		t.Fatal("应能计算意外值")
+	}
+	if math.Abs(s.Scaled()-(-27000)) > 1e-6 || s.Direction != DirectionBearish {
+		t.Errorf("换算单位后的意外值错误: %+v scaled=%v", s, s.Scaled())
+	}
+}
+
+func TestZScore(t *testing.T) {
+	if _, ok := ZScore(1, []float64{1, 2}); ok {
+		t.Error("样本不足时不应有Z分数")
+	}
+	if _, ok := ZScore(1, []float64{2, 2, 2}); ok {
+		t.Error("样本没有波动时不应有Z分数")
+	}
+
+	z, ok := ZScore(4, []float64{1, 2, 3})
+	if !ok || math.Abs(z-2) > 1e-9 {
+		t.Errorf("ZScore = %v, %v, 期望 2", z, ok)
+	}
+}
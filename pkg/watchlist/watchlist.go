@@ -0,0 +1,125 @@
+// Package watchlist 保存用户关注的指标和事件
+package watchlist
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/yourusername/fmcl/pkg/parser"
+)
+
+// Item 是一个关注项。Indicator 为去掉统计期前缀的指标名称或重要事件内容，
+// 因此关注 "1月CPI年率" 后，"2月CPI年率" 同样会被标记
+type Item struct {
+	Region    string `yaml:"region"`
+	Indicator string `yaml:"indicator"`
+}
+
+// Watchlist 是持久化到 YAML 文件的关注列表
+type Watchlist struct {
+	Path string
+
+	mu    sync.Mutex
+	items map[Item]bool
+}
+
+// 文件格式
+type file struct {
+	Items []Item `yaml:"items"`
+}
+
+// Load 读取关注列表，文件不存在时返回空列表
+func Load(path string) (*Watchlist, error) {
+	w := &Watchlist{Path: path, items: make(map[Item]bool)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return w, nil
+		}
+		return nil, fmt.Errorf("读取关注列表失败: %v", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("解析关注列表 %s 失败: %v", path, err)
+	}
+	for _, item := range f.Items {
+		w.items[normalize(item)] = true
+	}
+	return w, nil
+}
+
+// 统一使用去掉统计期前缀的名称
+func normalize(item Item) Item {
+	return Item{Region: item.Region, Indicator: parser.SeriesName(item.Indicator)}
+}
+
+// Items 返回按地区和名称排序的全部关注项
+func (w *Watchlist) Items() []Item {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	items := make([]Item, 0, len(w.items))
+	for item := range w.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Region != items[j].Region {
+			return items[i].Region < items[j].Region
+		}
+		return items[i].Indicator < items[j].Indicator
+	})
+	return items
+}
+
+// Len 返回关注项数量
+func (w *Watchlist) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.items)
+}
+
+// Contains 判断地区+指标（或事件）是否被关注
+func (w *Watchlist) Contains(region, indicator string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.items[normalize(Item{Region: region, Indicator: indicator})]
+}
+
+// Toggle 加入或移出关注并保存，返回操作后是否处于关注状态
+func (w *Watchlist) Toggle(region, indicator string) (bool, error) {
+	w.mu.Lock()
+	item := normalize(Item{Region: region, Indicator: indicator})
+	watched := !w.items[item]
+	if watched {
+		w.items[item] = true
+	} else {
+		delete(w.items, item)
+	}
+	w.mu.Unlock()
+
+	return watched, w.Save()
+}
+
+// Save 将关注列表写入文件
+func (w *Watchlist) Save() error {
+	data, err := yaml.Marshal(file{Items: w.Items()})
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(w.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(w.Path, data, 0644); err != nil {
+		return fmt.Errorf("保存关注列表失败: %v", err)
+	}
+	return nil
+}
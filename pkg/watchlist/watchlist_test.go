@@ -0,0 +1,41 @@
+package watchlist
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestToggleAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watchlist.yaml")
+	w, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Len() != 0 {
+		t.Fatalf("文件不存在时应为空列表")
+	}
+
+	for _, item := range []Item{{"美国", "1月CPI年率"}, {"中国", "1月官方制造业PMI"}, {"美国", "1月季调后非农就业人口(万)"}} {
+		if watched, err := w.Toggle(item.Region, item.Indicator); err != nil || !watched {
+			t.Fatalf("加入 %v: %v %v", item, watched, err)
+		}
+	}
+	if watched, err := w.Toggle("美国", "1月季调后非农就业人口(万)"); err != nil || watched {
+		t.Fatalf("再次切换应移出: %v %v", watched, err)
+	}
+
+	// 关注项按去掉统计期的名称匹配
+	if !w.Contains("美国", "2月CPI年率") || w.Contains("欧元区", "2月CPI年率") {
+		t.Error("应按地区和去掉统计期的指标名称匹配")
+	}
+
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Item{{"中国", "官方制造业PMI"}, {"美国", "CPI年率"}}
+	if got := reloaded.Items(); !reflect.DeepEqual(got, want) {
+		t.Errorf("重新加载 = %v, 期望 %v", got, want)
+	}
+}